@@ -0,0 +1,138 @@
+// Copyright New Relic, Inc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/goreleaser/goreleaser-pro/v2/pkg/config"
+	"gopkg.in/yaml.v3"
+)
+
+// itemKeys are the fields identifying an item of a project section, in order
+// of preference. Items without any are identified by their index.
+var itemKeys = []string{"id", "name_template"}
+
+// Diff compares a committed goreleaser file with the generated project,
+// ignoring key order and formatting. It describes each difference, naming
+// the differing items of sections such as builds, archives, nfpms or dockers.
+func Diff(committed []byte, generated config.Project) ([]string, error) {
+	var got map[string]any
+	if err := yaml.Unmarshal(committed, &got); err != nil {
+		return nil, fmt.Errorf("failed to parse committed file: %w", err)
+	}
+
+	b, err := yaml.Marshal(&generated)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal generated project: %w", err)
+	}
+	var want map[string]any
+	if err := yaml.Unmarshal(b, &want); err != nil {
+		return nil, fmt.Errorf("failed to parse generated project: %w", err)
+	}
+
+	var diffs []string
+	for _, key := range sortedKeys(got, want) {
+		gotItems, gotOK := got[key].([]any)
+		wantItems, wantOK := want[key].([]any)
+		if gotOK && wantOK {
+			diffs = append(diffs, diffItems(key, gotItems, wantItems)...)
+			continue
+		}
+
+		switch {
+		case got[key] == nil:
+			diffs = append(diffs, fmt.Sprintf("%s: missing from the committed file", key))
+		case want[key] == nil:
+			diffs = append(diffs, fmt.Sprintf("%s: no longer generated", key))
+		case !reflect.DeepEqual(got[key], want[key]):
+			diffs = append(diffs, fmt.Sprintf("%s: differs", key))
+		}
+	}
+
+	return diffs, nil
+}
+
+// diffItems matches the items of a section by their identifying field and
+// reports which of their fields differ.
+func diffItems(section string, got, want []any) []string {
+	gotByKey, gotOrder := indexItems(got)
+	wantByKey, wantOrder := indexItems(want)
+
+	var diffs []string
+	for _, key := range wantOrder {
+		name := fmt.Sprintf("%s[%s]", section, key)
+		gotItem, ok := gotByKey[key]
+		if !ok {
+			diffs = append(diffs, fmt.Sprintf("%s: missing from the committed file", name))
+			continue
+		}
+		if fields := diffFields(gotItem, wantByKey[key]); len(fields) > 0 {
+			diffs = append(diffs, fmt.Sprintf("%s: %s differ", name, strings.Join(fields, ", ")))
+		}
+	}
+	for _, key := range gotOrder {
+		if _, ok := wantByKey[key]; !ok {
+			diffs = append(diffs, fmt.Sprintf("%s[%s]: no longer generated", section, key))
+		}
+	}
+
+	return diffs
+}
+
+func indexItems(items []any) (map[string]any, []string) {
+	byKey := make(map[string]any, len(items))
+	order := make([]string, 0, len(items))
+
+	for i, item := range items {
+		key := fmt.Sprint(i)
+		if fields, ok := item.(map[string]any); ok {
+			for _, field := range itemKeys {
+				if value, ok := fields[field].(string); ok && value != "" {
+					key = value
+					break
+				}
+			}
+		}
+		byKey[key] = item
+		order = append(order, key)
+	}
+
+	return byKey, order
+}
+
+// diffFields lists the fields that differ between two items, or the item
+// itself if they aren't mappings.
+func diffFields(got, want any) []string {
+	gotFields, gotOK := got.(map[string]any)
+	wantFields, wantOK := want.(map[string]any)
+	if !gotOK || !wantOK {
+		if reflect.DeepEqual(got, want) {
+			return nil
+		}
+		return []string{"value"}
+	}
+
+	var fields []string
+	for _, key := range sortedKeys(gotFields, wantFields) {
+		if !reflect.DeepEqual(gotFields[key], wantFields[key]) {
+			fields = append(fields, key)
+		}
+	}
+	return fields
+}
+
+func sortedKeys(maps ...map[string]any) []string {
+	var keys []string
+	for _, m := range maps {
+		for key := range m {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	return slices.Compact(keys)
+}
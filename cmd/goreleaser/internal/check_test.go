@@ -0,0 +1,77 @@
+// Copyright New Relic, Inc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/goreleaser/goreleaser-pro/v2/pkg/config"
+)
+
+func TestDiff(t *testing.T) {
+	project := config.Project{
+		ProjectName: "nrdot-collector-releases",
+		Builds: []config.Build{
+			{ID: "dist", Binary: "dist", Goos: []string{"linux"}},
+			{ID: "dist-fips", Binary: "dist-fips", Goos: []string{"linux"}},
+		},
+		Dockers: []config.Docker{
+			{ID: "dist-amd64", Goarch: "amd64"},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		committed string
+		want      []string
+	}{
+		{
+			name: "reordered and reformatted",
+			committed: `
+dockers: [{goarch: amd64, id: dist-amd64}]
+builds:
+    - binary: dist
+      goos: [linux]
+      id: dist
+    - {id: dist-fips, binary: dist-fips, goos: [linux]}
+project_name: "nrdot-collector-releases"
+`,
+		},
+		{
+			name: "drifted",
+			committed: `
+project_name: nrdot-collector-releases
+builds:
+  - id: dist
+    binary: dist
+    goos: [linux, windows]
+  - id: dist-old
+dockers:
+  - id: dist-amd64
+    goarch: amd64
+release:
+  disable: "true"
+`,
+			want: []string{
+				"builds[dist]: goos differ",
+				"builds[dist-fips]: missing from the committed file",
+				"builds[dist-old]: no longer generated",
+				"release: no longer generated",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Diff([]byte(tt.committed), project)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Diff() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -22,59 +22,231 @@ package internal
 
 import (
 	"fmt"
+	"os"
 	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
 
 	"github.com/goreleaser/goreleaser-pro/v2/pkg/config"
 )
 
 const (
-	CoreDistro         = "nrdot-collector"
-	ExperimentalDistro = "nrdot-collector-experimental"
+	ConfigFile   = "config.yaml"
+	ManifestFile = "manifest.yaml"
+	DockerFile   = "Dockerfile"
+	MSIWxsFile   = "./windows/installer.wxs"
+	// PGOFile is the CPU profile the distribution is optimized with, if present.
+	PGOFile = "default.pgo"
+	// LicenseFile and NoticeFile are the license of the collector and the
+	// notices of its dependencies, both maintained by `make licenses`.
+	LicenseFile = "LICENSE"
+	NoticeFile  = "THIRD_PARTY_NOTICES.md"
 
-	ConfigFile = "config.yaml"
+	// Maintainer maintains the packages and images of every distribution.
+	Maintainer = "New Relic <otelcomm-team@newrelic.com>"
+	// APKKeyName names the public key apk packages are signed with, which
+	// users install in /etc/apk/keys.
+	APKKeyName = "otelcomm-team@newrelic.com.rsa.pub"
+
+	// DistributionPlaceholder is replaced with the distribution's name in the
+	// prefix of blob storage targets.
+	DistributionPlaceholder = "{{ .Distribution }}"
+	// DefaultBlobPrefix is the directory of blob storage targets without a prefix.
+	DefaultBlobPrefix = "nrdot-collector-releases/" + DistributionPlaceholder + "/{{ .Version }}/{{ .ShortCommit }}"
+
+	// CommitTimestamp and CommitDate pin the timestamps of builds, archives
+	// and packages to the released commit so that rebuilding it reproduces
+	// them bit for bit.
+	CommitTimestamp = "{{ .CommitTimestamp }}"
+	CommitDate      = "{{ .CommitDate }}"
+
+	// SignID identifies the signing config of the checksums in combined
+	// projects.
+	SignID = "gpg"
+	// CosignKeyEnv holds the cosign private key used for key-based image
+	// signing, its password is read by cosign from COSIGN_PASSWORD.
+	CosignKeyEnv = "COSIGN_PRIVATE_KEY"
 )
 
 type Distribution struct {
 	BaseName                string
-	FullName                string // dist or dist-fips
+	FullName                string // dist, dist-fips or dist-fips-native, see VariantName
+	Dir                     string // distribution directory relative to the goreleaser workdir, empty for single-variant projects
 	Fips                    bool
+	FipsModule              FipsModule // crypto module of the FIPS variant, empty otherwise
 	Goos                    []string
+	Architectures           []Architecture
 	IgnoredBuilds           []config.IgnoredBuild
+	Registries              []Registry
+	BlobTargets             []BlobTarget
 	IncludeConfig           bool
+	PackageFormats          []string
+	PackageOverrides        map[string]PackageOverride
+	Capabilities            []string // granted in the CAPABILITIES service mode
+	PGO                     bool     // whether the distribution directory has a PGOFile
 	SkipPackages            bool
 	SkipArchives            bool
 	SkipUploadToBlobStorage bool
 	SkipChecksums           bool
 	SkipSigning             bool
 	SkipMSI                 bool
+	SkipImages              bool
+	SkipDebugImages         bool
+	SkipUBIImages           bool
+	SkipDebugSymbols        bool
+	SkipImageSigning        bool
+	KeylessImageSigning     bool
+}
+
+// path resolves a file of the distribution directory relative to the
+// goreleaser workdir.
+func (d Distribution) path(file string) string {
+	if d.Dir == "" {
+		return file
+	}
+	return path.Join(d.Dir, file)
+}
+
+// Goarch lists the architectures dist is built for.
+func (d Distribution) Goarch() []string {
+	r := make([]string, 0, len(d.Architectures))
+	for _, arch := range d.Architectures {
+		r = append(r, arch.Goarch)
+	}
+	return r
+}
+
+// ImageGoarch lists the architectures dist's images are built for.
+func (d Distribution) ImageGoarch() []string {
+	var r []string
+	for _, arch := range d.Architectures {
+		if arch.Images {
+			r = append(r, arch.Goarch)
+		}
+	}
+	return r
+}
+
+// FipsMode selects which variants of a distribution are generated.
+type FipsMode string
+
+const (
+	FipsNone FipsMode = "false"
+	FipsOnly FipsMode = "true"
+	FipsBoth FipsMode = "both"
+)
+
+// ParseFipsMode parses the value of the generator's -fips flag.
+func ParseFipsMode(s string) (FipsMode, error) {
+	switch mode := FipsMode(s); mode {
+	case FipsNone, FipsOnly, FipsBoth:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("invalid FIPS mode %q, must be one of %s, %s or %s", s, FipsNone, FipsOnly, FipsBoth)
+	}
+}
+
+func (m FipsMode) variants() []bool {
+	switch m {
+	case FipsOnly:
+		return []bool{true}
+	case FipsBoth:
+		return []bool{false, true}
+	default:
+		return []bool{false}
+	}
+}
+
+// FipsModule selects the FIPS 140-3 validated cryptographic module the FIPS
+// variant is built with.
+type FipsModule string
+
+const (
+	// FipsModuleBoringCrypto links BoringSSL's module through cgo with
+	// GOEXPERIMENT=boringcrypto, which needs a C cross-compiler per
+	// architecture and static linking.
+	FipsModuleBoringCrypto FipsModule = "boringcrypto"
+	// FipsModuleNative uses the Go Cryptographic Module frozen at
+	// FipsNativeVersion and builds without cgo.
+	FipsModuleNative FipsModule = "native"
+)
+
+// ParseFipsModule parses the value of the generator's -fips-module flag.
+func ParseFipsModule(s string) (FipsModule, error) {
+	switch module := FipsModule(s); module {
+	case FipsModuleBoringCrypto, FipsModuleNative:
+		return module, nil
+	default:
+		return "", fmt.Errorf("invalid FIPS module %q, must be one of %s or %s", s, FipsModuleBoringCrypto, FipsModuleNative)
+	}
+}
+
+// VariantName names the variant of baseDist built with module, baseDist
+// itself without one. Binaries, packages, services and every other artifact
+// of the variant are named after it, so that the FIPS variants of both
+// modules can be released side by side.
+func VariantName(baseDist string, module FipsModule) string {
+	switch module {
+	case "":
+		return baseDist
+	case FipsModuleNative:
+		return baseDist + "-fips-native"
+	default:
+		return baseDist + "-fips"
+	}
+}
+
+// otherVariants lists the names of the variants of dist's base distribution
+// other than dist.
+func otherVariants(dist Distribution) []string {
+	var names []string
+	for _, module := range []FipsModule{"", FipsModuleBoringCrypto, FipsModuleNative} {
+		if name := VariantName(dist.BaseName, module); name != dist.FullName {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// variantSuffix is what the name of dist's variant appends to the name of
+// its base distribution, e.g. -fips.
+func variantSuffix(dist Distribution) string {
+	return strings.TrimPrefix(dist.FullName, dist.BaseName)
 }
 
 var (
-	Architectures = []string{"amd64", "arm64"}
-	FipsLdflags   = []string{"-w", "-linkmode external", "-extldflags '-static'"}
-	FipsGoTags    = []string{"netgo"}
+	FipsLdflags = []string{"-w", "-linkmode external", "-extldflags '-static'"}
+	FipsGoTags  = []string{"netgo"}
+	// FipsNativeVersion is the GOFIPS140 version of the Go Cryptographic
+	// Module native FIPS builds are frozen at, see
+	// https://go.dev/doc/security/fips140. fips/fips_native.go is only built
+	// with the build tag it sets.
+	FipsNativeVersion = "v1.0.0"
 )
 
-func Generate(distFlag string, fips bool) config.Project {
+// Generate builds the goreleaser project for the given distributions in
+// distsDir. A single distribution variant produces a project meant to run
+// from the distribution directory. Several distributions or variants are
+// combined into one project meant to run from distsDir.
+func Generate(distsDir string, distNames []string, fipsMode FipsMode, fipsModule FipsModule) (config.Project, error) {
 	projectName := "nrdot-collector-releases"
-	dist := NewDistribution(distFlag, fips)
-
-	return config.Project{
-		ProjectName:     projectName,
-		Checksum:        Checksum(dist),
-		Builds:          Builds(dist),
-		Archives:        Archives(dist),
-		NFPMs:           Packages(dist),
-		MSI:             MSI(dist),
-		Dockers:         DockerImages(dist),
-		DockerManifests: DockerManifests(dist),
-		Signs:           Signs(dist),
-		Version:         2,
-		Changelog:       config.Changelog{Disable: "true"},
+
+	dists, err := LoadDistributions(distsDir, distNames, fipsMode, fipsModule)
+	if err != nil {
+		return config.Project{}, err
+	}
+
+	project := config.Project{
+		ProjectName: projectName,
+		Checksum:    Checksum(dists),
+		Signs:       Signs(dists),
+		Version:     2,
+		Changelog:   config.Changelog{Disable: "true"},
 		Snapshot: config.Snapshot{
 			VersionTemplate: "{{ incpatch .Version }}-SNAPSHOT-{{.ShortCommit}}",
 		},
-		Blobs: Blobs(dist),
 		Release: config.Release{
 			Disable:              "true",
 			Draft:                true,
@@ -82,84 +254,158 @@ func Generate(distFlag string, fips bool) config.Project {
 			ReplaceExistingDraft: false,
 		},
 	}
+
+	for _, dist := range dists {
+		project.Builds = append(project.Builds, Builds(dist)...)
+		project.Archives = append(project.Archives, Archives(dist)...)
+		project.NFPMs = append(project.NFPMs, Packages(dist)...)
+		project.MSI = append(project.MSI, MSI(dist)...)
+		project.Dockers = append(project.Dockers, DockerImages(dist)...)
+		project.DockerManifests = append(project.DockerManifests, DockerManifests(dist)...)
+		project.DockerSigns = append(project.DockerSigns, DockerSigns(dist, len(dists) > 1)...)
+		project.Blobs = append(project.Blobs, Blobs(dist, len(dists) > 1)...)
+		project.SBOMs = append(project.SBOMs, SBOMs(dist)...)
+		project.SBOMs = append(project.SBOMs, Provenance(dist))
+	}
+
+	return project, nil
 }
 
-func NewDistribution(baseDist string, fips bool) Distribution {
-	fullName := baseDist
+// LoadDistributions loads and validates the release profiles of the given
+// distributions for every variant selected by fipsMode, building the FIPS
+// variant with fipsModule.
+func LoadDistributions(distsDir string, distNames []string, fipsMode FipsMode, fipsModule FipsModule) ([]Distribution, error) {
+	var dists []Distribution
+	seen := make(map[string]bool)
 
-	if fips {
-		fullName += "-fips"
-	}
+	for _, name := range distNames {
+		if seen[name] {
+			return nil, fmt.Errorf("distribution %s given more than once", name)
+		}
+		seen[name] = true
 
-	dist := Distribution{
-		BaseName: baseDist,
-		FullName: fullName,
-		Fips:     fips,
-		Goos:     []string{"linux", "windows"},
-		IgnoredBuilds: []config.IgnoredBuild{
-			{Goos: "windows", Goarch: "arm64"},
-		},
-		IncludeConfig:           true,
-		SkipUploadToBlobStorage: false,
-		SkipPackages:            false,
-		SkipArchives:            false,
-		SkipSigning:             false,
-		SkipChecksums:           false,
-		SkipMSI:                 false,
-	}
+		dir := filepath.Join(distsDir, name)
+		for _, fips := range fipsMode.variants() {
+			profile, err := LoadProfile(dir, fips)
+			if err != nil {
+				return nil, err
+			}
 
-	if isNoConfigDistro(baseDist) {
-		dist.IncludeConfig = false
-		dist.SkipMSI = true
+			dist := NewDistribution(name, false, profile)
+			if fips {
+				dist = NewFipsDistribution(name, fipsModule, profile)
+			}
+			if _, err := os.Stat(filepath.Join(dir, PGOFile)); err == nil {
+				dist.PGO = true
+			}
+			if err := profile.Validate(dist, dir); err != nil {
+				return nil, fmt.Errorf("invalid release profile for %s: %w", dist.FullName, err)
+			}
+			dists = append(dists, dist)
+		}
 	}
 
-	if isImageOnlyDistro(baseDist, fips) {
-		dist.Goos = []string{"linux"}
-		dist.IgnoredBuilds = nil
-		dist.SkipMSI = true
-		dist.SkipPackages = true
-		dist.SkipArchives = true
-		dist.SkipUploadToBlobStorage = true
-		dist.SkipSigning = true
-		dist.SkipChecksums = true
+	if len(dists) > 1 {
+		for i := range dists {
+			dists[i].Dir = dists[i].BaseName
+		}
 	}
 
-	if baseDist == ExperimentalDistro {
-		dist.Goos = []string{"linux"}
-		dist.IgnoredBuilds = nil
-		dist.SkipPackages = true
-		dist.SkipUploadToBlobStorage = true
-	}
+	return dists, nil
+}
 
-	return dist
+// NewDistribution derives the distribution settings from its release profile.
+// The FIPS variant is built with boringcrypto.
+func NewDistribution(baseDist string, fips bool, profile Profile) Distribution {
+	if fips {
+		return NewFipsDistribution(baseDist, FipsModuleBoringCrypto, profile)
+	}
+	return newDistribution(baseDist, "", profile)
 }
 
-func isNoConfigDistro(dist string) bool {
-	return dist == ExperimentalDistro
+// NewFipsDistribution derives the settings of the FIPS variant built with
+// module from the release profile.
+func NewFipsDistribution(baseDist string, module FipsModule, profile Profile) Distribution {
+	return newDistribution(baseDist, module, profile)
 }
 
-func isImageOnlyDistro(dist string, fips bool) bool {
-	return fips
+func newDistribution(baseDist string, fipsModule FipsModule, profile Profile) Distribution {
+	fips := fipsModule != ""
+
+	return Distribution{
+		BaseName:                baseDist,
+		FullName:                VariantName(baseDist, fipsModule),
+		Fips:                    fips,
+		FipsModule:              fipsModule,
+		Goos:                    profile.Goos,
+		Architectures:           profile.architectures(fipsModule),
+		IgnoredBuilds:           profile.Ignore,
+		Registries:              profile.Registries,
+		BlobTargets:             profile.BlobStorage,
+		IncludeConfig:           profile.IncludeConfig,
+		PackageFormats:          profile.Packages.formats(),
+		PackageOverrides:        profile.Packages.Overrides,
+		Capabilities:            profile.Packages.capabilities(),
+		SkipUploadToBlobStorage: !profile.Artifacts.Blobs,
+		SkipPackages:            !profile.Artifacts.Packages,
+		SkipArchives:            !profile.Artifacts.Archives,
+		SkipSigning:             !profile.Artifacts.Signing,
+		SkipChecksums:           !profile.Artifacts.Checksums,
+		SkipMSI:                 !profile.Artifacts.MSI,
+		SkipImages:              !profile.Artifacts.Images,
+		SkipDebugImages:         !profile.Artifacts.DebugImages,
+		SkipUBIImages:           !profile.Artifacts.UBIImages,
+		SkipDebugSymbols:        !profile.Artifacts.DebugSymbols,
+		SkipImageSigning:        !profile.ImageSigning.Enabled,
+		KeylessImageSigning:     profile.ImageSigning.Keyless,
+	}
 }
 
-func Blobs(dist Distribution) []config.Blob {
+// Blobs configures the uploads of a distribution to each of its blob storage
+// targets. In combined projects the upload is restricted to the
+// distribution's own artifacts and signatures, goreleaser always includes
+// all checksums, so their signatures are included too.
+func Blobs(dist Distribution, combined bool) []config.Blob {
 	if dist.SkipUploadToBlobStorage {
 		return nil
 	}
 
-	return []config.Blob{
-		Blob(dist),
+	var ids []string
+	if combined {
+		ids = ArtifactIDs(dist)
+		if !dist.SkipSigning {
+			ids = append(ids, SignID)
+			for _, sign := range DistributionSigns(dist) {
+				ids = append(ids, sign.ID)
+			}
+		}
+	}
+
+	r := make([]config.Blob, 0, len(dist.BlobTargets))
+	for _, target := range dist.BlobTargets {
+		blob := Blob(dist, target)
+		blob.IDs = ids
+		r = append(r, blob)
 	}
+
+	return r
 }
 
-func Blob(dist Distribution) config.Blob {
-	version := "{{ .Version }}"
-	shortCommit := "{{ .ShortCommit }}"
+// Blob configures an upload to a blob storage target.
+// https://goreleaser.com/customization/blob/
+func Blob(dist Distribution, target BlobTarget) config.Blob {
+	prefix := target.Prefix
+	if prefix == "" {
+		prefix = DefaultBlobPrefix
+	}
+
 	return config.Blob{
-		Provider:  "s3",
-		Region:    "us-east-1",
-		Bucket:    "nr-releases",
-		Directory: fmt.Sprintf("nrdot-collector-releases/%s/%s/%s", dist.FullName, version, shortCommit),
+		Provider:  target.Provider,
+		Endpoint:  target.Endpoint,
+		Region:    target.Region,
+		Bucket:    target.Bucket,
+		Directory: strings.ReplaceAll(prefix, DistributionPlaceholder, dist.FullName),
+		ACL:       target.ACL,
 	}
 }
 
@@ -169,77 +415,181 @@ func Builds(dist Distribution) []config.Build {
 	}
 }
 
+// BuildDir is the directory holding the OCB-generated sources of dist, one
+// per variant, e.g. _build-fips and _build-fips-native.
+func BuildDir(dist Distribution) string {
+	return dist.path("_build" + variantSuffix(dist))
+}
+
 // Build configures a goreleaser build.
 // https://goreleaser.com/customization/build/
 func Build(dist Distribution) config.Build {
-	dir := "_build"
+	dir := BuildDir(dist)
 	cgo := 0
 	ldflags := []string{"-s", "-w"}
+	flags := []string{"-trimpath", "-buildvcs=false"}
 	gotags := []string{}
 	goexperiment := ""
+	gofips140 := ""
 
 	var buildDetailsOverrides []config.BuildDetailsOverride
 
-	cc := map[string]string{
-		"amd64": "x86_64-linux-gnu-gcc",
-		"arm64": "aarch64-linux-gnu-gcc",
-	}
-
-	cxx := map[string]string{
-		"amd64": "x86_64-linux-gnu-g++",
-		"arm64": "aarch64-linux-gnu-g++",
+	if dist.PGO {
+		// relative to the build directory, which is a sibling of the profile
+		flags = append(flags, "-pgo=../"+PGOFile)
 	}
 
-	if dist.Fips {
-		dir = "_build-fips"
+	switch dist.FipsModule {
+	case FipsModuleNative:
+		// The frozen module enables FIPS 140-3 mode by default, binaries
+		// stay static without cgo.
+		gofips140 = FipsNativeVersion
+	case FipsModuleBoringCrypto:
 		cgo = 1
 		goexperiment = "boringcrypto"
 		ldflags = FipsLdflags
 		gotags = FipsGoTags
-		for _, arch := range Architectures {
+		for _, arch := range dist.Architectures {
 			buildDetailsOverrides = append(buildDetailsOverrides, config.BuildDetailsOverride{
 				Goos:   dist.Goos[0],
-				Goarch: arch,
+				Goarch: arch.Goarch,
 				BuildDetails: config.BuildDetails{
 					Env: []string{
-						fmt.Sprint("CC=", cc[arch]),
-						fmt.Sprint("CXX=", cxx[arch]),
+						fmt.Sprint("CC=", arch.CC),
+						fmt.Sprint("CXX=", arch.CXX),
 					},
 				},
 			})
 		}
 	}
 
+	env := []string{
+		fmt.Sprint("CGO_ENABLED=", cgo),
+		fmt.Sprint("GOEXPERIMENT=", goexperiment),
+	}
+	if gofips140 != "" {
+		env = append(env, "GOFIPS140="+gofips140)
+	}
+	env = append(env, "SOURCE_DATE_EPOCH="+CommitTimestamp)
+
+	static := cgo == 0 || slices.Contains(ldflags, "-extldflags '-static'")
+	var hooks config.Hooks
+	if !dist.SkipDebugSymbols {
+		// strip after the build so the debug symbols match the released binary
+		ldflags = slices.DeleteFunc(slices.Clone(ldflags), func(flag string) bool {
+			return flag == "-s" || flag == "-w"
+		})
+		hooks = append(hooks, DebugSymbolsSplit()...)
+	}
+	hooks = append(hooks, Inspection(dist, static, env, gotags))
+
 	return config.Build{
 		ID:     dist.FullName,
 		Dir:    dir,
 		Binary: dist.FullName,
+		Hooks: config.BuildHookConfig{
+			Post: hooks,
+		},
 		BuildDetails: config.BuildDetails{
-			Env:     []string{fmt.Sprint("CGO_ENABLED=", cgo), fmt.Sprint("GOEXPERIMENT=", goexperiment)},
-			Flags:   []string{"-trimpath"},
-			Ldflags: ldflags,
+			Env:     env,
+			Flags:   flags,
+			Ldflags: slices.Concat(ldflags, BuildInfoLdflags(dist)),
 			Tags:    gotags,
 		},
 		BuildDetailsOverrides: buildDetailsOverrides,
+		ModTimestamp:          CommitTimestamp,
 		Goos:                  dist.Goos,
-		Goarch:                Architectures,
+		Goarch:                dist.Goarch(),
 		Ignore:                dist.IgnoredBuilds,
 	}
 }
 
+// InspectionReport is the report nrdot-collector-builder writes next to each
+// binary it inspects.
+const InspectionReport = "inspect.json"
+
+// Inspection checks each binary goreleaser builds for dist against the build
+// settings: static linking, the build environment the toolchain records, the
+// tags and, for BoringCrypto, that crypto/tls/fipsonly and the module are
+// linked in. The build fails if a check doesn't pass.
+func Inspection(dist Distribution, static bool, env, tags []string) config.Hook {
+	args := []string{
+		"nrdot-collector-builder",
+		"inspect",
+		"--binary={{ .Path }}",
+		"--output={{ dir .Path }}/" + InspectionReport,
+	}
+	if static {
+		args = append(args, "--static")
+	}
+	for _, e := range env {
+		args = append(args, fmt.Sprint("--env=", e))
+	}
+	for _, tag := range tags {
+		args = append(args, fmt.Sprint("--tags=", tag))
+	}
+	if dist.FipsModule == FipsModuleBoringCrypto {
+		args = append(args, "--package=crypto/tls/fipsonly", "--package=crypto/internal/boring")
+	}
+	return config.Hook{Cmd: strings.Join(args, " ")}
+}
+
+// DebugID identifies the archive of dist's debug symbols.
+func DebugID(dist Distribution) string {
+	return dist.FullName + "-debug"
+}
+
+// Objcopy splits the debug symbols off the binaries. Unlike the host's GNU
+// objcopy, it handles every target architecture and windows binaries.
+const Objcopy = "llvm-objcopy"
+
+// DebugSymbolExt is appended to the path of a binary to name the file holding
+// its debug symbols.
+const DebugSymbolExt = ".debug"
+
+// DebugSymbolsSplit copies the symbol table and DWARF debug info of a binary
+// built without -s -w to a file next to it, then strips the binary and links
+// it to that file, so profiles and crash dumps of the released binaries can
+// be symbolized.
+func DebugSymbolsSplit() config.Hooks {
+	return config.Hooks{
+		{Cmd: fmt.Sprintf("%s --only-keep-debug {{ .Path }} {{ .Path }}%s", Objcopy, DebugSymbolExt)},
+		{Cmd: fmt.Sprintf("%s --strip-all --add-gnu-debuglink={{ .Path }}%s {{ .Path }}", Objcopy, DebugSymbolExt)},
+	}
+}
+
+// BuildInfoLdflags injects the build metadata reported by the collector into
+// the source generated by `nrdot-collector-builder manifest buildinfo`.
+func BuildInfoLdflags(dist Distribution) []string {
+	return []string{
+		"-X main.buildDistribution=" + dist.FullName,
+		"-X main.buildVersion={{ .Version }}",
+		"-X main.buildCommit={{ .FullCommit }}",
+		"-X main.buildDate=" + CommitDate,
+		fmt.Sprint("-X main.buildFips=", dist.Fips),
+		fmt.Sprint("-X main.buildFipsModule=", dist.FipsModule),
+	}
+}
+
 func Archives(dist Distribution) []config.Archive {
+	archives := []config.Archive{
+		Archive(dist),
+	}
 	if dist.SkipArchives {
 		// https://goreleaser.com/customization/archive/#do-not-archive
-		return []config.Archive{
+		archives = []config.Archive{
 			{
+				ID:      dist.FullName,
+				IDs:     []string{dist.FullName},
 				Formats: []string{"binary"},
 			},
 		}
 	}
 
-	return []config.Archive{
-		Archive(dist),
+	if !dist.SkipDebugSymbols {
+		archives = append(archives, DebugArchive(dist))
 	}
+	return archives
 }
 
 // Archive configures a goreleaser archive (tarball).
@@ -250,7 +600,9 @@ func Archive(dist Distribution) config.Archive {
 
 	if dist.IncludeConfig {
 		files = append(files, config.File{
-			Source: ConfigFile,
+			Source:      dist.path(ConfigFile),
+			StripParent: dist.Dir != "",
+			Info:        ReproducibleFileInfo(0o644),
 		})
 	}
 
@@ -258,6 +610,7 @@ func Archive(dist Distribution) config.Archive {
 		ID:           dist.FullName,
 		NameTemplate: "{{ .Binary }}_{{ .Version }}_{{ .Os }}_{{ .Arch }}{{ if .Arm }}v{{ .Arm }}{{ end }}{{ if .Mips }}_{{ .Mips }}{{ end }}",
 		IDs:          []string{dist.FullName},
+		BuildsInfo:   ReproducibleFileInfo(0o755),
 		Files:        files,
 		FormatOverrides: []config.FormatOverride{
 			{
@@ -267,6 +620,41 @@ func Archive(dist Distribution) config.Archive {
 	}
 }
 
+// DebugArchive configures the archive of dist's binaries along with their
+// debug symbols, named after the release archives.
+func DebugArchive(dist Distribution) config.Archive {
+	return config.Archive{
+		ID:           DebugID(dist),
+		NameTemplate: "{{ .Binary }}_{{ .Version }}_{{ .Os }}_{{ .Arch }}{{ if .Arm }}v{{ .Arm }}{{ end }}{{ if .Mips }}_{{ .Mips }}{{ end }}.debug",
+		IDs:          []string{dist.FullName},
+		BuildsInfo:   ReproducibleFileInfo(0o755),
+		Files: []config.File{
+			{
+				Source:      "{{ .ArtifactPath }}" + DebugSymbolExt,
+				StripParent: true,
+				Info:        ReproducibleFileInfo(0o644),
+			},
+		},
+		FormatOverrides: []config.FormatOverride{
+			{
+				Goos: "windows", Formats: []string{"zip"},
+			},
+		},
+	}
+}
+
+// ReproducibleFileInfo sets the owner and timestamp of an archived file
+// instead of taking them from the file system.
+// https://goreleaser.com/customization/builds/go/#reproducible-builds
+func ReproducibleFileInfo(mode os.FileMode) config.FileInfo {
+	return config.FileInfo{
+		Owner: "root",
+		Group: "root",
+		Mode:  mode,
+		MTime: CommitDate,
+	}
+}
+
 func Packages(dist Distribution) []config.NFPM {
 	if dist.SkipPackages {
 		return nil
@@ -280,37 +668,73 @@ func Packages(dist Distribution) []config.NFPM {
 // Package configures goreleaser to build a system package.
 // https://goreleaser.com/customization/nfpm/
 func Package(dist Distribution) config.NFPM {
-	nfpmContents := []config.NFPMContent{
-		{
-			Source:      fmt.Sprintf("%s.service", dist.FullName),
-			Destination: path.Join("/lib", "systemd", "system", fmt.Sprintf("%s.service", dist.FullName)),
-		},
-		{
-			Source:      fmt.Sprintf("%s.conf", dist.FullName),
-			Destination: path.Join("/etc", dist.FullName, fmt.Sprintf("%s.conf", dist.FullName)),
-			Type:        "config|noreplace",
-		},
+	// Contents restricted to a packager only go into its packages: the
+	// systemd unit is left out of apk packages, which get an OpenRC script.
+	nfpmContents := systemdContents(dist, config.NFPMContent{
+		Source:      dist.path(Unit(dist)),
+		Destination: path.Join("/lib", "systemd", "system", Unit(dist)),
+	})
+	for _, mode := range serviceModes {
+		nfpmContents = append(nfpmContents, systemdContents(dist, config.NFPMContent{
+			Source:      dist.path(DropInFile(dist, mode)),
+			Destination: dropInPath(dist, mode),
+		})...)
+	}
+	if slices.Contains(dist.PackageFormats, "apk") {
+		nfpmContents = append(nfpmContents, config.NFPMContent{
+			Source:      dist.path(OpenRCScript(dist)),
+			Destination: path.Join("/etc", "init.d", dist.FullName),
+			Packager:    "apk",
+			FileInfo:    config.FileInfo{Mode: 0o755},
+		})
 	}
+	nfpmContents = append(nfpmContents, config.NFPMContent{
+		Source:      dist.path(EnvironmentFile(dist)),
+		Destination: path.Join("/etc", dist.FullName, EnvironmentFile(dist)),
+		Type:        "config|noreplace",
+	})
 
 	if dist.IncludeConfig {
 		nfpmContents = append(nfpmContents, config.NFPMContent{
-			Source:      ConfigFile,
+			Source:      dist.path(ConfigFile),
 			Destination: path.Join("/etc", dist.FullName, ConfigFile),
 			Type:        "config",
 		})
 	}
+	for i := range nfpmContents {
+		nfpmContents[i].FileInfo.MTime = CommitDate
+	}
+
+	// FIPS packages run a service of their own, side by side installs
+	// would compete for the same ports.
+	var conflicts []string
+	if dist.Fips {
+		conflicts = otherVariants(dist)
+	}
+
+	// apk doesn't read GPG signatures, its packages are signed with an RSA
+	// key of their own.
+	var apk config.NFPMAPK
+	if slices.Contains(dist.PackageFormats, "apk") {
+		apk.Signature = config.NFPMAPKSignature{
+			KeyFile: "{{ .Env.APK_KEY_PATH }}",
+			KeyName: APKKeyName,
+		}
+	}
+	var archLinux config.NFPMArchLinux
+	if slices.Contains(dist.PackageFormats, "archlinux") {
+		archLinux.Packager = Maintainer
+	}
+
 	return config.NFPM{
 		ID:          dist.FullName,
 		IDs:         []string{dist.FullName},
-		Formats:     []string{"deb", "rpm"},
+		Formats:     dist.PackageFormats,
 		License:     "Apache 2.0",
 		Description: fmt.Sprintf("NRDOT Collector - %s", dist.FullName),
-		Maintainer:  "New Relic <otelcomm-team@newrelic.com>",
-		Overrides: map[string]config.NFPMOverridables{
-			"rpm": {
-				Dependencies: []string{"/bin/sh"},
-			},
-		},
+		Maintainer:  Maintainer,
+		MTime:       CommitDate,
+		Overrides:   packageOverrides(dist),
 		NFPMOverridables: config.NFPMOverridables{
 			PackageName: dist.FullName,
 			FileNameTemplate: "{{ .PackageName }}_{{ .Version }}_{{ .Os }}_" +
@@ -323,41 +747,227 @@ func Package(dist Distribution) config.NFPM {
 				"{{- with .Mips }}_{{ . }}{{- end }}" +
 				"{{- if not (eq .Amd64 \"v1\") }}{{ .Amd64 }}{{- end }}",
 			Scripts: config.NFPMScripts{
-				PreInstall:  "preinstall.sh",
-				PostInstall: "postinstall.sh",
-				PreRemove:   "preremove.sh",
+				PreInstall:  dist.path(PackageScript(dist, "preinstall")),
+				PostInstall: dist.path(PackageScript(dist, "postinstall")),
+				PreRemove:   dist.path(PackageScript(dist, "preremove")),
+				PostRemove:  dist.path(PackageScript(dist, "postremove")),
 			},
-			Contents: nfpmContents,
+			Contents:  nfpmContents,
+			Conflicts: conflicts,
 			RPM: config.NFPMRPM{
 				Signature: config.NFPMRPMSignature{
 					KeyFile: "{{ .Env.GPG_KEY_PATH }}",
 				},
+				// rpm runs the scripts of the replaced package last on
+				// upgrades, posttrans runs once they are done.
+				Scripts: config.NFPMRPMScripts{
+					PostTrans: dist.path(PackageScript(dist, "posttrans")),
+				},
 			},
 			Deb: config.NFPMDeb{
 				Signature: config.NFPMDebSignature{
 					KeyFile: "{{ .Env.GPG_KEY_PATH }}",
 				},
 			},
+			APK:       apk,
+			ArchLinux: archLinux,
 		},
 	}
 }
 
-func DockerImageTags(dist Distribution) []string {
-	tags := []string{}
-	if dist.Fips {
-		tags = append(tags, "{{ .Version }}-fips")
-	} else {
-		tags = append(tags, "{{ .Version }}")
-		tags = append(tags, "latest")
+// systemdContents restricts content to the package formats of dist that
+// install a systemd unit.
+func systemdContents(dist Distribution, content config.NFPMContent) []config.NFPMContent {
+	systemd := systemdFormats(dist)
+	if len(systemd) == len(dist.PackageFormats) {
+		return []config.NFPMContent{content}
+	}
+
+	contents := make([]config.NFPMContent, 0, len(systemd))
+	for _, format := range systemd {
+		content.Packager = format
+		contents = append(contents, content)
+	}
+	return contents
+}
+
+// systemdFormats lists the package formats of dist that install a systemd
+// unit, which is all of them but apk.
+func systemdFormats(dist Distribution) []string {
+	var formats []string
+	for _, format := range dist.PackageFormats {
+		if format != "apk" {
+			formats = append(formats, format)
+		}
+	}
+	return formats
+}
+
+// Unit names the systemd unit of dist.
+func Unit(dist Distribution) string {
+	return fmt.Sprintf("%s.service", dist.FullName)
+}
+
+// EnvironmentFile names the file the service of dist reads its options from.
+func EnvironmentFile(dist Distribution) string {
+	return fmt.Sprintf("%s.conf", dist.FullName)
+}
+
+// OpenRCScript names the OpenRC init script of dist, installed by apk
+// packages.
+func OpenRCScript(dist Distribution) string {
+	return fmt.Sprintf("%s.openrc", dist.FullName)
+}
+
+// packageOverrides translates the profile's overrides of the formats dist is
+// packaged in.
+func packageOverrides(dist Distribution) map[string]config.NFPMOverridables {
+	scriptPath := func(file string) string {
+		if file == "" {
+			return ""
+		}
+		return dist.path(file)
+	}
+
+	overrides := make(map[string]config.NFPMOverridables)
+	for _, format := range dist.PackageFormats {
+		override, ok := dist.PackageOverrides[format]
+		if !ok {
+			continue
+		}
+		overridables := config.NFPMOverridables{
+			Dependencies: override.Dependencies,
+			Scripts: config.NFPMScripts{
+				PreInstall:  scriptPath(override.Scripts.PreInstall),
+				PostInstall: scriptPath(override.Scripts.PostInstall),
+				PreRemove:   scriptPath(override.Scripts.PreRemove),
+				PostRemove:  scriptPath(override.Scripts.PostRemove),
+			},
+		}
+		switch format {
+		case "apk":
+			overridables.APK.Scripts = config.NFPMAPKScripts{
+				PreUpgrade:  scriptPath(override.Scripts.PreUpgrade),
+				PostUpgrade: scriptPath(override.Scripts.PostUpgrade),
+			}
+		case "archlinux":
+			overridables.ArchLinux.Scripts = config.NFPMArchLinuxScripts{
+				PreUpgrade:  scriptPath(override.Scripts.PreUpgrade),
+				PostUpgrade: scriptPath(override.Scripts.PostUpgrade),
+			}
+		}
+		overrides[format] = overridables
+	}
+	return overrides
+}
+
+// PackageScript names a package script of dist. FIPS variants have their own
+// scripts since they manage their service.
+func PackageScript(dist Distribution, script string) string {
+	return script + variantSuffix(dist) + ".sh"
+}
+
+// ImageTag is a tag template of an image. Floating tags move along with new
+// releases.
+type ImageTag struct {
+	Name     string
+	Floating bool
+}
+
+// ImageFlavor is a kind of container image built for a distribution, from
+// its own Dockerfile and published with its own tags and manifests.
+type ImageFlavor string
+
+const (
+	// ImageFlavorDefault is the production image, built from scratch.
+	ImageFlavorDefault ImageFlavor = ""
+	// ImageFlavorDebug adds a shell and network tools for troubleshooting.
+	ImageFlavorDebug ImageFlavor = "debug"
+	// ImageFlavorUBI is built on Red Hat's Universal Base Image, with the
+	// labels and license files OpenShift certification requires.
+	ImageFlavorUBI ImageFlavor = "ubi"
+)
+
+// Dockerfile names the Dockerfile of the flavor in the distribution
+// directory.
+func (f ImageFlavor) Dockerfile() string {
+	if f == ImageFlavorDefault {
+		return DockerFile
+	}
+	return fmt.Sprintf("%s.%s", DockerFile, f)
+}
+
+// ImageFlavors lists the flavors of the images built for dist.
+func (d Distribution) ImageFlavors() []ImageFlavor {
+	flavors := []ImageFlavor{ImageFlavorDefault}
+	if !d.SkipDebugImages {
+		flavors = append(flavors, ImageFlavorDebug)
+	}
+	if !d.SkipUBIImages {
+		flavors = append(flavors, ImageFlavorUBI)
+	}
+	return flavors
+}
+
+// DockerImageTags resolves the tag policy of registry for the images of dist
+// in flavor. FIPS images and flavors other than the default are suffixed,
+// e.g. 2.3.1-fips-debug or 2.3.1-fips-native with Go's own FIPS module, and
+// never tagged latest.
+func DockerImageTags(dist Distribution, registry Registry, flavor ImageFlavor) []ImageTag {
+	policy := registry.Tags
+	if len(policy) == 0 {
+		policy = DefaultTags
+	}
+
+	suffix := variantSuffix(dist)
+	if flavor != ImageFlavorDefault {
+		suffix += "-" + string(flavor)
+	}
+
+	tags := []ImageTag{}
+	for _, tag := range policy {
+		switch tag {
+		case TagVersion:
+			tags = append(tags, ImageTag{Name: "{{ .Version }}" + suffix})
+		case TagMajorMinor:
+			tags = append(tags, ImageTag{Name: "{{ .Major }}.{{ .Minor }}" + suffix, Floating: true})
+		case TagMajor:
+			tags = append(tags, ImageTag{Name: "{{ .Major }}" + suffix, Floating: true})
+		case TagLatest:
+			if suffix == "" {
+				tags = append(tags, ImageTag{Name: "latest", Floating: true})
+			}
+		}
 	}
 	return tags
 }
 
+// ImageName is the reference of the image of dist tagged with tag in
+// registry, suffixed with arch for single-platform images. References with a
+// floating tag render empty for snapshots and pre-releases, which makes
+// goreleaser skip them.
+func ImageName(registry string, dist Distribution, tag ImageTag, arch string) string {
+	name := fmt.Sprintf("%s/%s:%s", registry, dist.BaseName, tag.Name)
+	if arch != "" {
+		name = fmt.Sprintf("%s-%s", name, arch)
+	}
+	if tag.Floating {
+		name = fmt.Sprintf("{{ if not (or .IsSnapshot .Prerelease) }}%s{{ end }}", name)
+	}
+	return name
+}
+
 func DockerImages(dist Distribution) []config.Docker {
+	if dist.SkipImages {
+		return nil
+	}
+
 	var r []config.Docker
 
-	for _, arch := range Architectures {
-		r = append(r, DockerImage(dist, arch))
+	for _, flavor := range dist.ImageFlavors() {
+		for _, arch := range dist.ImageGoarch() {
+			r = append(r, DockerImage(dist, flavor, arch))
+		}
 	}
 
 	return r
@@ -365,98 +975,224 @@ func DockerImages(dist Distribution) []config.Docker {
 
 // DockerImage configures goreleaser to build a container image.
 // https://goreleaser.com/customization/docker/
-func DockerImage(dist Distribution, arch string) config.Docker {
-
-	dockerFile := "Dockerfile"
-
+func DockerImage(dist Distribution, flavor ImageFlavor, arch string) config.Docker {
 	imageTemplates := make([]string, 0)
-	for _, tag := range DockerImageTags(dist) {
-		imageTemplates = append(
-			imageTemplates,
-			fmt.Sprintf("{{ .Env.REGISTRY }}/%s:%s-%s", dist.BaseName, tag, arch),
-		)
+	for _, registry := range dist.Registries {
+		for _, tag := range DockerImageTags(dist, registry, flavor) {
+			imageTemplates = append(imageTemplates, ImageName(registry.Address, dist, tag, arch))
+		}
 	}
 
 	label := func(name, template string) string {
 		return fmt.Sprintf("--label=org.opencontainers.image.%s={{%s}}", name, template)
 	}
 
+	buildFlags := []string{
+		"--pull",
+		fmt.Sprintf("--platform=linux/%s", arch),
+		label("created", ".Date"),
+		label("name", ".ProjectName"),
+		label("revision", ".FullCommit"),
+		label("version", ".Version"),
+		label("source", ".GitURL"),
+		"--label=org.opencontainers.image.licenses=Apache-2.0",
+		fmt.Sprint("--build-arg=DIST_NAME=", dist.FullName),
+	}
+
 	files := make([]string, 0)
 	if dist.IncludeConfig {
-		files = append(files, ConfigFile)
+		files = append(files, dist.path(ConfigFile))
+		// extra files keep their path inside the build context
+		if dist.Dir != "" {
+			buildFlags = append(buildFlags, fmt.Sprint("--build-arg=CONFIG_FILE=", dist.path(ConfigFile)))
+		}
+	}
+	if flavor == ImageFlavorUBI {
+		buildFlags = append(buildFlags, ubiLabels(dist)...)
+		files = append(files, dist.path(LicenseFile), dist.path(NoticeFile))
+		if dist.Dir != "" {
+			buildFlags = append(buildFlags,
+				fmt.Sprint("--build-arg=LICENSE_FILE=", dist.path(LicenseFile)),
+				fmt.Sprint("--build-arg=NOTICE_FILE=", dist.path(NoticeFile)),
+			)
+		}
+	}
+
+	id := fmt.Sprintf("%s-%s", dist.FullName, arch)
+	if flavor != ImageFlavorDefault {
+		id = fmt.Sprintf("%s-%s-%s", dist.FullName, flavor, arch)
 	}
 
 	return config.Docker{
+		ID:             id,
+		IDs:            []string{dist.FullName},
 		ImageTemplates: imageTemplates,
-		Dockerfile:     dockerFile,
-
-		Use: "buildx",
-		BuildFlagTemplates: []string{
-			"--pull",
-			fmt.Sprintf("--platform=linux/%s", arch),
-			label("created", ".Date"),
-			label("name", ".ProjectName"),
-			label("revision", ".FullCommit"),
-			label("version", ".Version"),
-			label("source", ".GitURL"),
-			"--label=org.opencontainers.image.licenses=Apache-2.0",
-			fmt.Sprint("--build-arg=DIST_NAME=", dist.FullName),
-		},
-		Files:  files,
-		Goos:   "linux",
-		Goarch: arch,
+		Dockerfile:     dist.path(flavor.Dockerfile()),
+
+		Use:                "buildx",
+		BuildFlagTemplates: buildFlags,
+		Files:              files,
+		Goos:               "linux",
+		Goarch:             arch,
 	}
 }
 
+// ubiLabels are the labels Red Hat requires of certified images, on top of
+// the OCI ones every image has.
+func ubiLabels(dist Distribution) []string {
+	description := fmt.Sprintf("NRDOT Collector - %s", dist.FullName)
+	labels := [][2]string{
+		{"name", dist.FullName},
+		{"vendor", "New Relic"},
+		{"maintainer", Maintainer},
+		{"version", "{{ .Version }}"},
+		{"release", "{{ .ShortCommit }}"},
+		{"summary", description},
+		{"description", description},
+		{"url", "{{ .GitURL }}"},
+		{"io.k8s.display-name", "NRDOT Collector"},
+		{"io.k8s.description", description},
+		{"io.openshift.tags", "opentelemetry,collector,newrelic"},
+	}
+
+	flags := make([]string, 0, len(labels))
+	for _, label := range labels {
+		flags = append(flags, fmt.Sprintf("--label=%s=%s", label[0], label[1]))
+	}
+	return flags
+}
+
 func DockerManifests(dist Distribution) []config.DockerManifest {
+	if dist.SkipImages {
+		return nil
+	}
+
 	r := make([]config.DockerManifest, 0)
 
-	for _, tag := range DockerImageTags(dist) {
-		r = append(r, DockerManifest(tag, dist))
+	for _, flavor := range dist.ImageFlavors() {
+		for _, registry := range dist.Registries {
+			for _, tag := range DockerImageTags(dist, registry, flavor) {
+				manifest := DockerManifest(registry.Address, tag, dist)
+				manifest.ID = DockerManifestID(registry.Address, tag, dist)
+				r = append(r, manifest)
+			}
+		}
 	}
 
 	return r
 }
 
+// manifestIDSeparators are the runs of characters of registries and tags
+// that manifest IDs replace with a dash.
+var manifestIDSeparators = regexp.MustCompile(`[^a-z0-9]+`)
+
+// DockerManifestID identifies the manifest of dist tagged with tag in
+// registry. Manifest IDs must be unique and their names are templates, so the
+// ID is built from the registry and the tag, e.g. dist-docker-io-newrelic-
+// major-minor-fips, and stays the same whatever the order of either.
+func DockerManifestID(registry string, tag ImageTag, dist Distribution) string {
+	name := strings.ToLower(registry + "-" + tag.Name)
+	return dist.FullName + "-" + strings.Trim(manifestIDSeparators.ReplaceAllString(name, "-"), "-")
+}
+
 // DockerManifest configures goreleaser to build a multi-arch container image manifest.
 // https://goreleaser.com/customization/docker_manifest/
-func DockerManifest(version string, dist Distribution) config.DockerManifest {
+func DockerManifest(registry string, tag ImageTag, dist Distribution) config.DockerManifest {
 	var imageTemplates []string
 
-	for _, arch := range Architectures {
-		imageTemplates = append(
-			imageTemplates,
-			fmt.Sprintf("{{ .Env.REGISTRY }}/%s:%s-%s", dist.BaseName, version, arch),
-		)
+	for _, arch := range dist.ImageGoarch() {
+		imageTemplates = append(imageTemplates, ImageName(registry, dist, tag, arch))
 	}
 
 	return config.DockerManifest{
-		NameTemplate:   fmt.Sprintf("{{ .Env.REGISTRY }}/%s:%s", dist.BaseName, version),
+		NameTemplate:   ImageName(registry, dist, tag, ""),
 		ImageTemplates: imageTemplates,
 	}
 }
 
-func Checksum(dist Distribution) config.Checksum {
-	if dist.SkipChecksums {
+// ArtifactIDs lists the goreleaser IDs of the artifacts produced for dist:
+// builds, archives, packages and MSIs share the distribution's name, each
+// SBOM config and the provenance have their own.
+func ArtifactIDs(dist Distribution) []string {
+	ids := []string{dist.FullName}
+	if !dist.SkipDebugSymbols {
+		ids = append(ids, DebugID(dist))
+	}
+	for _, sbom := range SBOMs(dist) {
+		ids = append(ids, sbom.ID)
+	}
+	return append(ids, Provenance(dist).ID)
+}
+
+// Checksum configures the checksums of all distributions that ask for them.
+func Checksum(dists []Distribution) config.Checksum {
+	var ids []string
+	for _, dist := range dists {
+		if !dist.SkipChecksums {
+			ids = append(ids, ArtifactIDs(dist)...)
+		}
+	}
+
+	if len(ids) == 0 {
 		return config.Checksum{
 			Disable: true,
 		}
 	}
+	if !slices.ContainsFunc(dists, func(dist Distribution) bool { return dist.SkipChecksums }) {
+		ids = nil
+	}
 	return config.Checksum{
 		NameTemplate: "{{ .ArtifactName }}.sum",
 		Split:        true,
 		Algorithm:    "sha256",
+		IDs:          ids,
 	}
 }
 
-func Signs(dist Distribution) []config.Sign {
-	if dist.SkipSigning {
+// signedArtifacts are the kinds of artifacts each distribution signs in
+// combined projects, all of them but the checksums.
+var signedArtifacts = []string{"archive", "binary", "package", "sbom"}
+
+// Signs configures signing of the artifacts of all distributions that ask
+// for it. Combined projects sign the checksums, which goreleaser never
+// filters by ID, once, and the other artifacts with signing configs of each
+// distribution, so that its uploads only include its own signatures.
+func Signs(dists []Distribution) []config.Sign {
+	signed := slices.DeleteFunc(slices.Clone(dists), func(dist Distribution) bool {
+		return dist.SkipSigning
+	})
+	if len(signed) == 0 {
 		return nil
 	}
+	if len(dists) == 1 {
+		return []config.Sign{
+			SignAllArtifacts(),
+		}
+	}
 
-	return []config.Sign{
-		SignAllArtifacts(),
+	checksums := SignAllArtifacts()
+	checksums.ID = SignID
+	checksums.Artifacts = "checksum"
+	signs := []config.Sign{checksums}
+	for _, dist := range signed {
+		signs = append(signs, DistributionSigns(dist)...)
 	}
+	return signs
+}
+
+// DistributionSigns configures signing of the artifacts of dist but the
+// checksums in combined projects. goreleaser can only leave the checksums
+// out by kind of artifact, so each kind has a config of its own.
+func DistributionSigns(dist Distribution) []config.Sign {
+	signs := make([]config.Sign, 0, len(signedArtifacts))
+	for _, kind := range signedArtifacts {
+		sign := SignAllArtifacts()
+		sign.ID = fmt.Sprintf("%s-gpg-%s", dist.FullName, kind)
+		sign.Artifacts = kind
+		sign.IDs = ArtifactIDs(dist)
+		signs = append(signs, sign)
+	}
+	return signs
 }
 
 func SignAllArtifacts() config.Sign {
@@ -483,12 +1219,264 @@ func MSI(dist Distribution) []config.MSI {
 	return []config.MSI{
 		{
 			ID:   dist.FullName,
+			IDs:  []string{dist.FullName},
 			Name: fmt.Sprintf("%s_{{ .Version }}_windows_{{ .MsiArch }}", dist.FullName), // installer filename
-			WXS:  "./windows/installer.wxs",
+			WXS:  dist.path(MSIWxsFile),
 			Files: []string{
-				"config.yaml",
+				dist.path(ConfigFile),
 			},
 			Replace: false,
 		},
 	}
 }
+
+var sbomFormats = []struct {
+	name       string
+	syftFlag   string
+	extension  string
+	attestType string // cosign predicate type
+}{
+	{name: "spdx", syftFlag: "spdx-json", extension: "spdx.json", attestType: "spdxjson"},
+	{name: "cyclonedx", syftFlag: "cyclonedx-json", extension: "cdx.json", attestType: "cyclonedx"},
+}
+
+// SBOMs configures SPDX and CycloneDX bills of materials for the artifacts of
+// a distribution. syft reads the Go modules compiled into the collector from
+// the build info of the binaries inside archives, packages and images, while
+// the source SBOM catalogs the OCB-generated go.mod.
+// https://goreleaser.com/customization/sbom/
+func SBOMs(dist Distribution) []config.SBOM {
+	var r []config.SBOM
+
+	if !dist.SkipArchives {
+		r = append(r, ArtifactSBOMs(dist, "archive", "{{ .ArtifactName }}")...)
+	}
+	if !dist.SkipPackages {
+		r = append(r, ArtifactSBOMs(dist, "package", "{{ .ArtifactName }}")...)
+	}
+	// binaries end up in images and, without archives, are released as is
+	if dist.SkipArchives || !dist.SkipImages {
+		r = append(r, ArtifactSBOMs(dist, "binary", "{{ .Binary }}_{{ .Version }}_{{ .Os }}_{{ .Arch }}")...)
+	}
+
+	return append(r, SourceSBOM(dist))
+}
+
+// ArtifactSBOMs catalogs every artifact of the given kind, one config per
+// format as goreleaser only supports multiple documents for the source SBOM.
+func ArtifactSBOMs(dist Distribution, artifacts string, name string) []config.SBOM {
+	var r []config.SBOM
+
+	for _, format := range sbomFormats {
+		r = append(r, config.SBOM{
+			ID:        fmt.Sprintf("%s-%s-%s", dist.FullName, artifacts, format.name),
+			Artifacts: artifacts,
+			IDs:       []string{dist.FullName},
+			Documents: []string{fmt.Sprintf("%s.%s", name, format.extension)},
+			Args:      []string{"$artifact", "--output", fmt.Sprintf("%s=$document", format.syftFlag)},
+		})
+	}
+
+	return r
+}
+
+// SourceSBOM catalogs the Go modules of the OCB-generated sources. syft runs
+// from goreleaser's dist folder, hence the path relative to its parent.
+func SourceSBOM(dist Distribution) config.SBOM {
+	args := []string{fmt.Sprint("dir:", path.Join("..", BuildDir(dist)))}
+	var documents []string
+
+	for i, format := range sbomFormats {
+		documents = append(documents, SourceSBOMDocument(dist, format.extension))
+		args = append(args, "--output", fmt.Sprintf("%s=$document%d", format.syftFlag, i))
+	}
+
+	return config.SBOM{
+		ID:        fmt.Sprintf("%s-source", dist.FullName),
+		Artifacts: "any",
+		Documents: documents,
+		Args:      args,
+	}
+}
+
+// SourceSBOMDocument is the name of the source SBOM of dist in the given
+// format, relative to goreleaser's dist folder.
+func SourceSBOMDocument(dist Distribution, extension string) string {
+	return fmt.Sprintf("%s_{{ .Version }}_source.%s", dist.FullName, extension)
+}
+
+// ImageIDs lists the goreleaser IDs of the images and manifests of dist.
+func ImageIDs(dist Distribution) []string {
+	var ids []string
+	for _, image := range DockerImages(dist) {
+		ids = append(ids, image.ID)
+	}
+	for _, manifest := range DockerManifests(dist) {
+		ids = append(ids, manifest.ID)
+	}
+	return ids
+}
+
+// DockerSigns configures cosign to sign the images and manifests of dist and
+// to attach its SBOMs as in-toto attestations. Image signing is independent
+// of the GPG signing of files. In combined projects each distribution only
+// signs its own images.
+// https://goreleaser.com/customization/docker_sign/
+func DockerSigns(dist Distribution, combined bool) []config.Sign {
+	if dist.SkipImages || dist.SkipImageSigning {
+		return nil
+	}
+
+	signs := append([]config.Sign{ImageSign(dist)}, ImageAttestations(dist)...)
+	if combined {
+		for i := range signs {
+			signs[i].IDs = ImageIDs(dist)
+		}
+	}
+
+	return signs
+}
+
+// ImageSign signs every image and manifest of dist.
+func ImageSign(dist Distribution) config.Sign {
+	return config.Sign{
+		ID:        fmt.Sprintf("%s-cosign", dist.FullName),
+		Cmd:       "cosign",
+		Artifacts: "all",
+		Args:      cosignArgs(dist, "sign"),
+	}
+}
+
+// ImageAttestations attests every image and manifest of dist with the source
+// SBOM, which catalogs the Go modules of the collector binary they ship, and
+// with the build provenance. The sign command runs from the goreleaser
+// workdir, next to the dist folder.
+func ImageAttestations(dist Distribution) []config.Sign {
+	r := []config.Sign{
+		{
+			ID:        fmt.Sprintf("%s-attest-provenance", dist.FullName),
+			Cmd:       "cosign",
+			Artifacts: "all",
+			Args: cosignArgs(dist, "attest",
+				"--type=slsaprovenance1",
+				fmt.Sprint("--predicate=", path.Join("dist", ProvenancePredicateDocument(dist))),
+			),
+		},
+	}
+
+	for _, format := range sbomFormats {
+		r = append(r, config.Sign{
+			ID:        fmt.Sprintf("%s-attest-%s", dist.FullName, format.name),
+			Cmd:       "cosign",
+			Artifacts: "all",
+			Args: cosignArgs(dist, "attest",
+				fmt.Sprint("--type=", format.attestType),
+				fmt.Sprint("--predicate=", path.Join("dist", SourceSBOMDocument(dist, format.extension))),
+			),
+		})
+	}
+
+	return r
+}
+
+// cosignArgs builds the arguments of a cosign command for the image being
+// signed. Keyless signing requests a certificate for the OIDC identity of the
+// CI job from Fulcio, otherwise the key in CosignKeyEnv is used.
+func cosignArgs(dist Distribution, cmd string, flags ...string) []string {
+	args := []string{cmd}
+	if !dist.KeylessImageSigning {
+		args = append(args, fmt.Sprintf("--key=env://%s", CosignKeyEnv))
+	}
+	args = append(args, flags...)
+	return append(args, "${artifact}@${digest}", "--yes")
+}
+
+// ProvenanceDocument is the name of the SLSA provenance statement of dist,
+// relative to goreleaser's dist folder.
+func ProvenanceDocument(dist Distribution) string {
+	return fmt.Sprintf("%s_{{ .Version }}.provenance.json", dist.FullName)
+}
+
+// ProvenancePredicateDocument is the name of the bare provenance predicate
+// of dist that cosign wraps into image attestations.
+func ProvenancePredicateDocument(dist Distribution) string {
+	return fmt.Sprintf("%s_{{ .Version }}.provenance-predicate.json", dist.FullName)
+}
+
+// Provenance records how the artifacts of dist were built as a SLSA v1
+// provenance: the manifest digest and OTel versions, the Go toolchain and the
+// settings of Build. It is generated by nrdot-collector-builder from
+// goreleaser's dist folder once archives and packages exist, and is
+// released like an SBOM so it is checksummed, signed and uploaded with them.
+// https://goreleaser.com/customization/sbom/
+func Provenance(dist Distribution) config.SBOM {
+	build := Build(dist)
+
+	args := []string{
+		"manifest",
+		"provenance",
+		fmt.Sprint("--config=", path.Join("..", dist.path(ManifestFile))),
+		"--output=$document0",
+		"--predicate-output=$document1",
+		fmt.Sprint("--distribution=", dist.FullName),
+		"--version={{ .Version }}",
+		fmt.Sprint("--fips=", dist.Fips),
+		fmt.Sprint("--fips-module=", dist.FipsModule),
+		"--source={{ .GitURL }}",
+		"--commit={{ .FullCommit }}",
+	}
+	for _, env := range build.Env {
+		args = append(args, fmt.Sprint("--env=", env))
+	}
+	for _, flag := range build.Flags {
+		args = append(args, fmt.Sprint("--flags=", flag))
+	}
+	for _, ldflag := range build.Ldflags {
+		args = append(args, fmt.Sprint("--ldflags=", ldflag))
+	}
+	for _, tag := range build.Tags {
+		args = append(args, fmt.Sprint("--tags=", tag))
+	}
+	for _, subject := range provenanceSubjects(dist) {
+		args = append(args, fmt.Sprint("--subject=", subject))
+	}
+
+	return config.SBOM{
+		ID:        fmt.Sprintf("%s-provenance", dist.FullName),
+		Cmd:       "nrdot-collector-builder",
+		Artifacts: "any",
+		Documents: []string{ProvenanceDocument(dist), ProvenancePredicateDocument(dist)},
+		Args:      args,
+	}
+}
+
+// packageExtensions are the file extensions nfpm gives the packages of each
+// format.
+var packageExtensions = map[string]string{
+	"deb":       "deb",
+	"rpm":       "rpm",
+	"apk":       "apk",
+	"archlinux": "pkg.tar.zst",
+}
+
+// provenanceSubjects lists glob patterns, relative to the dist folder, of the
+// files released for dist. Images are attested with the provenance instead,
+// their digests aren't known yet when it is generated.
+func provenanceSubjects(dist Distribution) []string {
+	// binaries are built into <build id>_<os>_<arch>[_<variant>] folders
+	subjects := []string{fmt.Sprintf("%s_*/%s*", dist.FullName, dist.FullName)}
+
+	if !dist.SkipArchives {
+		subjects = append(subjects, fmt.Sprintf("%s_*.tar.gz", dist.FullName), fmt.Sprintf("%s_*.zip", dist.FullName))
+	}
+	if !dist.SkipPackages {
+		for _, format := range dist.PackageFormats {
+			subjects = append(subjects, fmt.Sprintf("%s_*.%s", dist.FullName, packageExtensions[format]))
+		}
+	}
+	if !dist.SkipMSI {
+		subjects = append(subjects, fmt.Sprintf("%s_*.msi", dist.FullName))
+	}
+
+	return subjects
+}
@@ -0,0 +1,810 @@
+// Copyright New Relic, Inc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+const distsDir = "../../../distributions"
+
+// The committed goreleaser files serve as golden files, regenerate them with
+// `make generate-goreleaser` when the generator changes.
+func TestGenerate_Golden(t *testing.T) {
+	profiles, err := filepath.Glob(filepath.Join(distsDir, "*", ProfileFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(profiles) == 0 {
+		t.Fatal("no distributions found")
+	}
+
+	var distNames []string
+	for _, profile := range profiles {
+		name := filepath.Base(filepath.Dir(profile))
+		distNames = append(distNames, name)
+
+		t.Run(name, func(t *testing.T) {
+			assertGolden(t, filepath.Join(distsDir, name, ".goreleaser.yaml"), []string{name}, FipsNone, FipsModuleBoringCrypto)
+		})
+		t.Run(name+"-fips", func(t *testing.T) {
+			assertGolden(t, filepath.Join(distsDir, name, ".goreleaser-fips.yaml"), []string{name}, FipsOnly, FipsModuleBoringCrypto)
+		})
+		t.Run(name+"-fips-native", func(t *testing.T) {
+			assertGolden(t, filepath.Join(distsDir, name, ".goreleaser-fips-native.yaml"), []string{name}, FipsOnly, FipsModuleNative)
+		})
+	}
+
+	t.Run("combined", func(t *testing.T) {
+		assertGolden(t, filepath.Join(distsDir, ".goreleaser.yaml"), distNames, FipsBoth, FipsModuleBoringCrypto)
+	})
+}
+
+func assertGolden(t *testing.T, golden string, distNames []string, fipsMode FipsMode, fipsModule FipsModule) {
+	t.Helper()
+
+	project, err := Generate(distsDir, distNames, fipsMode, fipsModule)
+	if err != nil {
+		t.Fatalf("failed to generate project: %v", err)
+	}
+
+	var got bytes.Buffer
+	e := yaml.NewEncoder(&got)
+	e.SetIndent(2)
+	if err := e.Encode(&project); err != nil {
+		t.Fatal(err)
+	}
+
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got.Bytes(), want) {
+		t.Errorf("%s is out of date, run `make generate-goreleaser`", golden)
+	}
+}
+
+func TestSBOMs(t *testing.T) {
+	tests := []struct {
+		name string
+		dist Distribution
+		want []string
+	}{
+		{
+			name: "all artifacts",
+			dist: Distribution{FullName: "dist"},
+			want: []string{
+				"dist-archive-spdx", "dist-archive-cyclonedx",
+				"dist-package-spdx", "dist-package-cyclonedx",
+				"dist-binary-spdx", "dist-binary-cyclonedx",
+				"dist-source",
+			},
+		},
+		{
+			name: "archives only",
+			dist: Distribution{FullName: "dist", SkipPackages: true, SkipImages: true},
+			want: []string{"dist-archive-spdx", "dist-archive-cyclonedx", "dist-source"},
+		},
+		{
+			name: "image only",
+			dist: Distribution{FullName: "dist-fips", Fips: true, SkipArchives: true, SkipPackages: true},
+			want: []string{"dist-fips-binary-spdx", "dist-fips-binary-cyclonedx", "dist-fips-source"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, sbom := range SBOMs(tt.dist) {
+				got = append(got, sbom.ID)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("SBOMs() ids = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSignsAndChecksum_CoverSBOMs(t *testing.T) {
+	signed := Distribution{BaseName: "dist", FullName: "dist", SkipPackages: true, SkipImages: true}
+	unsigned := Distribution{BaseName: "dist", FullName: "dist-fips", Fips: true, SkipArchives: true, SkipPackages: true, SkipSigning: true, SkipChecksums: true}
+	dists := []Distribution{signed, unsigned}
+
+	want := ArtifactIDs(signed)
+	if got := Checksum(dists).IDs; !slices.Equal(got, want) {
+		t.Errorf("Checksum().IDs = %v, want %v", got, want)
+	}
+	signs := Signs(dists)
+	if len(signs) != 1+len(signedArtifacts) {
+		t.Fatalf("Signs() returned %d configs, want the checksums and one per kind of artifact", len(signs))
+	}
+	if signs[0].ID != SignID || signs[0].Artifacts != "checksum" {
+		t.Errorf("Signs()[0] = %+v, want the checksums signed once", signs[0])
+	}
+	for _, sign := range signs[1:] {
+		if !slices.Equal(sign.IDs, want) || sign.Artifacts == "checksum" || sign.Artifacts == "all" {
+			t.Errorf("Signs() %s = %+v, want the artifacts of %s but the checksums", sign.ID, sign, signed.FullName)
+		}
+	}
+
+	if signs := Signs([]Distribution{signed}); len(signs) != 1 || signs[0].Artifacts != "all" || signs[0].IDs != nil {
+		t.Errorf("Signs() = %+v for a single distribution, want every artifact signed", signs)
+	}
+}
+
+func TestDockerSigns(t *testing.T) {
+	dist := Distribution{
+		BaseName:        "dist",
+		FullName:        "dist-fips",
+		Fips:            true,
+		Architectures:   []Architecture{{Goarch: "amd64", Images: true}, {Goarch: "arm64", Images: true}},
+		Registries:      []Registry{{Address: "registry.example.com"}},
+		SkipArchives:    true,
+		SkipPackages:    true,
+		SkipSigning:     true,
+		SkipChecksums:   true,
+		SkipDebugImages: true,
+		SkipUBIImages:   true,
+	}
+
+	signs := DockerSigns(dist, true)
+	// signature, provenance and SBOM attestations
+	if want := 2 + len(sbomFormats); len(signs) != want {
+		t.Fatalf("DockerSigns() returned %d configs, want %d", len(signs), want)
+	}
+	wantIDs := []string{
+		"dist-fips-amd64",
+		"dist-fips-arm64",
+		"dist-fips-registry-example-com-version-fips",
+		"dist-fips-registry-example-com-major-minor-fips",
+		"dist-fips-registry-example-com-major-fips",
+	}
+	for _, sign := range signs {
+		if !slices.Equal(sign.IDs, wantIDs) {
+			t.Errorf("%s: IDs = %v, want %v", sign.ID, sign.IDs, wantIDs)
+		}
+		if !slices.Contains(sign.Args, "--key=env://"+CosignKeyEnv) {
+			t.Errorf("%s: args %v don't use the cosign key", sign.ID, sign.Args)
+		}
+	}
+
+	dist.KeylessImageSigning = true
+	for _, sign := range DockerSigns(dist, false) {
+		if sign.IDs != nil {
+			t.Errorf("%s: IDs = %v, want none outside combined projects", sign.ID, sign.IDs)
+		}
+		if slices.Contains(sign.Args, "--key=env://"+CosignKeyEnv) {
+			t.Errorf("%s: keyless args %v use the cosign key", sign.ID, sign.Args)
+		}
+	}
+
+	dist.SkipImageSigning = true
+	if signs := DockerSigns(dist, false); signs != nil {
+		t.Errorf("DockerSigns() = %v, want none when image signing is disabled", signs)
+	}
+}
+
+func TestProvenance(t *testing.T) {
+	dist := Distribution{BaseName: "dist", FullName: "dist-fips", Dir: "dist", Fips: true, FipsModule: FipsModuleBoringCrypto, Goos: []string{"linux"}, Architectures: []Architecture{{Goarch: "amd64"}}, SkipArchives: true, SkipPackages: true, SkipMSI: true}
+
+	provenance := Provenance(dist)
+	if !slices.Contains(ArtifactIDs(dist), provenance.ID) {
+		t.Errorf("ArtifactIDs() = %v, missing %s", ArtifactIDs(dist), provenance.ID)
+	}
+	for _, arg := range []string{
+		"--config=../dist/manifest.yaml",
+		"--fips=true",
+		"--fips-module=boringcrypto",
+		"--env=CGO_ENABLED=1",
+		"--env=GOEXPERIMENT=boringcrypto",
+		"--subject=dist-fips_*/dist-fips*",
+	} {
+		if !slices.Contains(provenance.Args, arg) {
+			t.Errorf("Provenance().Args = %v, missing %s", provenance.Args, arg)
+		}
+	}
+}
+
+func TestDockerManifests_RegistryTags(t *testing.T) {
+	dist := Distribution{
+		BaseName: "dist",
+		FullName: "dist",
+		Architectures: []Architecture{
+			{Goarch: "amd64", Images: true},
+			{Goarch: "arm64", Images: true},
+		},
+		Registries: []Registry{
+			{Address: "docker.io/newrelic"},
+			{Address: "harbor.example.com/otel", Tags: []string{TagVersion}},
+		},
+		SkipDebugImages: true,
+		SkipUBIImages:   true,
+	}
+
+	var got []string
+	for _, manifest := range DockerManifests(dist) {
+		got = append(got, manifest.NameTemplate)
+	}
+	want := []string{
+		"docker.io/newrelic/dist:{{ .Version }}",
+		"{{ if not (or .IsSnapshot .Prerelease) }}docker.io/newrelic/dist:{{ .Major }}.{{ .Minor }}{{ end }}",
+		"{{ if not (or .IsSnapshot .Prerelease) }}docker.io/newrelic/dist:{{ .Major }}{{ end }}",
+		"{{ if not (or .IsSnapshot .Prerelease) }}docker.io/newrelic/dist:latest{{ end }}",
+		"harbor.example.com/otel/dist:{{ .Version }}",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("DockerManifests() names = %v, want %v", got, want)
+	}
+
+	// IDs don't depend on the order of the registries
+	ids := ImageIDs(dist)
+	if want := "dist-harbor-example-com-otel-version"; !slices.Contains(ids, want) {
+		t.Errorf("ImageIDs() = %v, want %s", ids, want)
+	}
+	slices.Reverse(dist.Registries)
+	reordered := ImageIDs(dist)
+	slices.Sort(ids)
+	slices.Sort(reordered)
+	if !slices.Equal(ids, reordered) || len(slices.Compact(reordered)) != len(ids) {
+		t.Errorf("ImageIDs() = %v after reordering the registries, want the same unique IDs %v", reordered, ids)
+	}
+	slices.Reverse(dist.Registries)
+
+	images := DockerImage(dist, ImageFlavorDefault, "amd64").ImageTemplates
+	if slices.ContainsFunc(images, func(image string) bool { return strings.Contains(image, "harbor.example.com/otel/dist:latest") }) {
+		t.Errorf("DockerImage() = %v, want no latest tag in harbor.example.com", images)
+	}
+
+	dist.Fips = true
+	dist.FullName = "dist-fips"
+	want = []string{"{{ .Version }}-fips", "{{ .Major }}.{{ .Minor }}-fips", "{{ .Major }}-fips"}
+	var tags []string
+	for _, tag := range DockerImageTags(dist, dist.Registries[0], ImageFlavorDefault) {
+		tags = append(tags, tag.Name)
+	}
+	if !slices.Equal(tags, want) {
+		t.Errorf("DockerImageTags() = %v, want %v", tags, want)
+	}
+}
+
+func TestImageName_FloatingTags(t *testing.T) {
+	dist := Distribution{BaseName: "dist"}
+
+	if got, want := ImageName("registry", dist, ImageTag{Name: "{{ .Version }}"}, "amd64"), "registry/dist:{{ .Version }}-amd64"; got != want {
+		t.Errorf("ImageName() = %q, want %q", got, want)
+	}
+
+	// goreleaser skips images and manifests whose name renders empty
+	floating := ImageName("registry", dist, ImageTag{Name: "{{ .Major }}", Floating: true}, "")
+	if want := "{{ if not (or .IsSnapshot .Prerelease) }}registry/dist:{{ .Major }}{{ end }}"; floating != want {
+		t.Errorf("ImageName() = %q, want %q", floating, want)
+	}
+}
+
+func TestBlobs_Targets(t *testing.T) {
+	dist := Distribution{
+		FullName: "dist",
+		BlobTargets: []BlobTarget{
+			{Provider: "s3", Region: "us-east-1", Bucket: "nr-releases"},
+			{Provider: "s3", Endpoint: "https://minio.example.com", Bucket: "releases", Prefix: "{{ .Distribution }}/{{ .Tag }}", ACL: "private"},
+			{Provider: "gs", Bucket: "releases"},
+		},
+	}
+
+	blobs := Blobs(dist, true)
+	if len(blobs) != 3 {
+		t.Fatalf("Blobs() returned %d configs, want 3", len(blobs))
+	}
+	if got, want := blobs[0].Directory, "nrdot-collector-releases/dist/{{ .Version }}/{{ .ShortCommit }}"; got != want {
+		t.Errorf("Blobs()[0].Directory = %q, want %q", got, want)
+	}
+	if got, want := blobs[1].Directory, "dist/{{ .Tag }}"; got != want {
+		t.Errorf("Blobs()[1].Directory = %q, want %q", got, want)
+	}
+	if blobs[1].Endpoint != "https://minio.example.com" || blobs[1].ACL != "private" {
+		t.Errorf("Blobs()[1] = %+v, want endpoint and acl of the target", blobs[1])
+	}
+	want := append(ArtifactIDs(dist), SignID)
+	for _, sign := range DistributionSigns(dist) {
+		want = append(want, sign.ID)
+	}
+	for _, blob := range blobs {
+		if !slices.Equal(blob.IDs, want) {
+			t.Errorf("%s: IDs = %v, want the distribution's artifacts and signatures", blob.Bucket, blob.IDs)
+		}
+	}
+
+	// the signatures of other distributions aren't uploaded
+	other := Distribution{FullName: "dist-fips", BlobTargets: dist.BlobTargets}
+	for _, sign := range DistributionSigns(other) {
+		if slices.Contains(blobs[0].IDs, sign.ID) {
+			t.Errorf("Blobs() IDs = %v, want no signatures of %s", blobs[0].IDs, other.FullName)
+		}
+	}
+}
+
+func TestArchitectureMatrix(t *testing.T) {
+	profile := Profile{
+		Goos: []string{"linux"},
+		Architectures: []Architecture{
+			{Goarch: "amd64", CC: "x86_64-linux-gnu-gcc", CXX: "x86_64-linux-gnu-g++", Fips: true, Images: true},
+			{Goarch: "ppc64le", CC: "powerpc64le-linux-gnu-gcc", CXX: "powerpc64le-linux-gnu-g++", Images: true},
+			{Goarch: "s390x"},
+		},
+		Artifacts:  Artifacts{Images: true},
+		Registries: []Registry{{Address: "registry.example.com"}},
+	}
+
+	dist := NewDistribution("dist", false, profile)
+	if got, want := Build(dist).Goarch, []string{"amd64", "ppc64le", "s390x"}; !slices.Equal(got, want) {
+		t.Errorf("Build().Goarch = %v, want %v", got, want)
+	}
+	var images []string
+	for _, image := range DockerImages(dist) {
+		images = append(images, image.Goarch)
+	}
+	if want := []string{"amd64", "ppc64le"}; !slices.Equal(images, want) {
+		t.Errorf("DockerImages() archs = %v, want %v", images, want)
+	}
+	if got := DockerManifests(dist)[0].ImageTemplates; len(got) != 2 {
+		t.Errorf("DockerManifests()[0].ImageTemplates = %v, want one image per arch with images", got)
+	}
+
+	fips := NewDistribution("dist", true, profile)
+	build := Build(fips)
+	if got, want := build.Goarch, []string{"amd64"}; !slices.Equal(got, want) {
+		t.Errorf("FIPS Build().Goarch = %v, want %v", got, want)
+	}
+	if len(build.BuildDetailsOverrides) != 1 || !slices.Contains(build.BuildDetailsOverrides[0].Env, "CC=x86_64-linux-gnu-gcc") {
+		t.Errorf("FIPS Build().BuildDetailsOverrides = %+v, want the amd64 cross-compiler", build.BuildDetailsOverrides)
+	}
+}
+
+func TestBuild_FipsNative(t *testing.T) {
+	profile := Profile{
+		Goos: []string{"linux"},
+		Architectures: []Architecture{
+			{Goarch: "amd64", CC: "x86_64-linux-gnu-gcc", CXX: "x86_64-linux-gnu-g++", Fips: true, Images: true},
+			{Goarch: "s390x", CC: "s390x-linux-gnu-gcc", CXX: "s390x-linux-gnu-g++", Images: true},
+		},
+		Artifacts:  Artifacts{Images: true},
+		Registries: []Registry{{Address: "registry.example.com", Tags: []string{TagVersion}}},
+	}
+
+	dist := NewFipsDistribution("dist", FipsModuleNative, profile)
+	// only BoringCrypto is restricted to the architectures supporting fips
+	if goarch := dist.Goarch(); !slices.Equal(goarch, []string{"amd64", "s390x"}) {
+		t.Errorf("Goarch() = %v, want every architecture", goarch)
+	}
+	if goarch := NewDistribution("dist", true, profile).Goarch(); !slices.Equal(goarch, []string{"amd64"}) {
+		t.Errorf("Goarch() of the boringcrypto variant = %v, want [amd64]", goarch)
+	}
+	build := Build(dist)
+	for _, want := range []string{"CGO_ENABLED=0", "GOEXPERIMENT=", "GOFIPS140=" + FipsNativeVersion} {
+		if !slices.Contains(build.Env, want) {
+			t.Errorf("Build().Env = %v, want %q", build.Env, want)
+		}
+	}
+	if len(build.BuildDetailsOverrides) != 0 {
+		t.Errorf("Build().BuildDetailsOverrides = %+v, want no cross-compilers", build.BuildDetailsOverrides)
+	}
+	if slices.Contains(build.Ldflags, "-linkmode external") || len(build.Tags) != 0 {
+		t.Errorf("Build() = %+v, want the ldflags and tags of a cgo-free build", build.BuildDetails)
+	}
+
+	var tags []string
+	for _, tag := range DockerImageTags(dist, profile.Registries[0], ImageFlavorDebug) {
+		tags = append(tags, tag.Name)
+	}
+	if want := []string{"{{ .Version }}-fips-native-debug"}; !slices.Equal(tags, want) {
+		t.Errorf("DockerImageTags() = %v, want %v", tags, want)
+	}
+
+	// every artifact of the native variant is named apart from the boringcrypto one
+	boring := NewDistribution("dist", true, profile)
+	if dist.FullName != "dist-fips-native" || boring.FullName != "dist-fips" {
+		t.Errorf("FullName = %s and %s, want dist-fips-native and dist-fips", dist.FullName, boring.FullName)
+	}
+	target := BlobTarget{Provider: "s3", Bucket: "bucket", Prefix: "releases/" + DistributionPlaceholder}
+	for name, of := range map[string]func(Distribution) string{
+		"build":         func(d Distribution) string { return Build(d).ID + " " + Build(d).Binary },
+		"sources":       func(d Distribution) string { return BuildDir(d) },
+		"package":       func(d Distribution) string { return Package(d).PackageName },
+		"package files": func(d Distribution) string { return Unit(d) + " " + PackageScript(d, "postinstall") },
+		"blobs":         func(d Distribution) string { return Blob(d, target).Directory },
+		"provenance":    func(d Distribution) string { return ProvenanceDocument(d) },
+	} {
+		if of(dist) == of(boring) {
+			t.Errorf("%s of the native variant = %q, the same as the boringcrypto one", name, of(dist))
+		}
+	}
+	if pkg := Package(dist); !slices.Equal(pkg.Conflicts, []string{"dist", "dist-fips"}) {
+		t.Errorf("Package().Conflicts = %v, want the other variants", pkg.Conflicts)
+	}
+
+	if _, err := ParseFipsModule("openssl"); err == nil {
+		t.Error("ParseFipsModule() accepted an unknown module")
+	}
+}
+
+func TestBuild_BuildInfoLdflags(t *testing.T) {
+	profile := Profile{Goos: []string{"linux"}, Architectures: []Architecture{{Goarch: "amd64", Fips: true}}}
+
+	for _, module := range []FipsModule{"", FipsModuleBoringCrypto, FipsModuleNative} {
+		dist := NewDistribution("dist", false, profile)
+		if module != "" {
+			dist = NewFipsDistribution("dist", module, profile)
+		}
+		ldflags := Build(dist).Ldflags
+		for _, want := range []string{
+			"-X main.buildDistribution=" + dist.FullName,
+			"-X main.buildCommit={{ .FullCommit }}",
+			fmt.Sprint("-X main.buildFips=", dist.Fips),
+			fmt.Sprint("-X main.buildFipsModule=", module),
+		} {
+			if !slices.Contains(ldflags, want) {
+				t.Errorf("Build(%s).Ldflags = %v, want %q", dist.FullName, ldflags, want)
+			}
+		}
+	}
+
+	if got := len(FipsLdflags); got != 3 {
+		t.Errorf("FipsLdflags was modified, got %d flags", got)
+	}
+}
+
+func TestBuild_Inspection(t *testing.T) {
+	profile := Profile{Goos: []string{"linux"}, Architectures: []Architecture{{Goarch: "amd64", Fips: true}}}
+
+	tests := []struct {
+		name   string
+		fips   bool
+		module FipsModule
+		want   []string
+		absent []string
+	}{
+		{"plain", false, "", []string{"--static", "--env=CGO_ENABLED=0", "--env=GOEXPERIMENT="}, []string{"--tags=netgo", "--package="}},
+		{"boringcrypto", true, FipsModuleBoringCrypto, []string{"--static", "--env=GOEXPERIMENT=boringcrypto", "--tags=netgo", "--package=crypto/tls/fipsonly"}, nil},
+		{"native", true, FipsModuleNative, []string{"--static", "--env=GOFIPS140=" + FipsNativeVersion}, []string{"--tags=netgo", "--package="}},
+	}
+	for _, tt := range tests {
+		dist := NewDistribution("dist", false, profile)
+		if tt.fips {
+			dist = NewFipsDistribution("dist", tt.module, profile)
+		}
+		build := Build(dist)
+		if len(build.Hooks.Post) != 1 {
+			t.Fatalf("%s: Build().Hooks.Post = %+v, want the inspection", tt.name, build.Hooks.Post)
+		}
+		cmd := build.Hooks.Post[0].Cmd
+		if !strings.Contains(cmd, " --output={{ dir .Path }}/"+InspectionReport+" ") {
+			t.Errorf("%s: inspection %q doesn't write its report next to the binary", tt.name, cmd)
+		}
+		args := strings.Fields(cmd)
+		for _, want := range tt.want {
+			if !slices.Contains(args, want) {
+				t.Errorf("%s: inspection %v, want %q", tt.name, args, want)
+			}
+		}
+		for _, absent := range tt.absent {
+			if slices.ContainsFunc(args, func(arg string) bool { return strings.HasPrefix(arg, absent) }) {
+				t.Errorf("%s: inspection %v, want no %q", tt.name, args, absent)
+			}
+		}
+	}
+}
+
+func TestDebugSymbols(t *testing.T) {
+	profile := Profile{
+		Goos:          []string{"linux"},
+		Architectures: []Architecture{{Goarch: "amd64", Fips: true}},
+		Artifacts:     Artifacts{Packages: true, Blobs: true, DebugSymbols: true},
+	}
+
+	for _, fips := range []bool{false, true} {
+		dist := NewDistribution("dist", fips, profile)
+		released := NewDistribution("dist", fips, Profile{
+			Goos:          profile.Goos,
+			Architectures: profile.Architectures,
+			Artifacts:     Artifacts{Packages: true, Blobs: true},
+		})
+
+		builds := Builds(dist)
+		if len(builds) != 1 {
+			t.Fatalf("Builds(fips=%v) = %+v, want a single build", fips, builds)
+		}
+		build, release := builds[0], Build(released)
+		if slices.Contains(build.Ldflags, "-s") || slices.Contains(build.Ldflags, "-w") {
+			t.Errorf("build of fips=%v strips symbols before they're split: %v", fips, build.Ldflags)
+		}
+		if !slices.Contains(release.Ldflags, "-w") {
+			t.Errorf("build of fips=%v without debug symbols doesn't strip symbols: %v", fips, release.Ldflags)
+		}
+		stripped := slices.DeleteFunc(slices.Clone(release.Ldflags), func(flag string) bool {
+			return flag == "-s" || flag == "-w"
+		})
+		if !slices.Equal(build.Ldflags, stripped) {
+			t.Errorf("ldflags of fips=%v = %v, want the release ldflags %v without -s -w", fips, build.Ldflags, stripped)
+		}
+		if !slices.Equal(build.Flags, release.Flags) || !slices.Equal(build.Env, release.Env) || !slices.Equal(build.Tags, release.Tags) {
+			t.Errorf("build of fips=%v = %+v, want the release build details %+v", fips, build.BuildDetails, release.BuildDetails)
+		}
+
+		hooks := build.Hooks.Post
+		if len(hooks) != 3 {
+			t.Fatalf("post hooks of fips=%v = %+v, want the debug symbols split then the inspection", fips, hooks)
+		}
+		if !strings.Contains(hooks[0].Cmd, "--only-keep-debug") || !strings.Contains(hooks[1].Cmd, "--strip-all") {
+			t.Errorf("post hooks of fips=%v = %+v, want the debug symbols kept before stripping", fips, hooks)
+		}
+		if !strings.Contains(hooks[2].Cmd, " inspect ") {
+			t.Errorf("last post hook of fips=%v = %q, want the stripped binary inspected", fips, hooks[2].Cmd)
+		}
+		if got := release.Hooks.Post; len(got) != 1 {
+			t.Errorf("post hooks of fips=%v without debug symbols = %+v, want only the inspection", fips, got)
+		}
+
+		archives := Archives(dist)
+		debug := archives[len(archives)-1]
+		if debug.ID != DebugID(dist) || !slices.Equal(debug.IDs, []string{dist.FullName}) {
+			t.Errorf("debug archive = %+v, want %s archiving the %s binaries", debug, DebugID(dist), dist.FullName)
+		}
+		if len(debug.Files) != 1 || debug.Files[0].Source != "{{ .ArtifactPath }}"+DebugSymbolExt {
+			t.Errorf("debug archive files = %+v, want the debug symbols next to each binary", debug.Files)
+		}
+		if !slices.Contains(ArtifactIDs(dist), DebugID(dist)) {
+			t.Errorf("ArtifactIDs() = %v, want the debug archives checksummed, signed and uploaded", ArtifactIDs(dist))
+		}
+	}
+}
+
+func TestLoadDistributions_PGO(t *testing.T) {
+	distsDir := t.TempDir()
+	dir := filepath.Join(distsDir, "dist")
+	profile := `goos: [linux]
+architectures:
+  - goarch: amd64
+    cc: x86_64-linux-gnu-gcc
+    cxx: x86_64-linux-gnu-g++
+    fips: true
+artifacts:
+  archives: true
+fips: {}
+`
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for file, content := range map[string]string{ProfileFile: profile, ManifestFile: ""} {
+		if err := os.WriteFile(filepath.Join(dir, file), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dists, err := LoadDistributions(distsDir, []string{"dist"}, FipsBoth, FipsModuleBoringCrypto)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, dist := range dists {
+		if slices.ContainsFunc(Build(dist).Flags, func(flag string) bool { return strings.HasPrefix(flag, "-pgo") }) {
+			t.Errorf("Build(%s).Flags = %v, want no -pgo without %s", dist.FullName, Build(dist).Flags, PGOFile)
+		}
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, PGOFile), nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	dists, err = LoadDistributions(distsDir, []string{"dist"}, FipsBoth, FipsModuleBoringCrypto)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, dist := range dists {
+		// combined projects run from distsDir, the build directory is dist/_build
+		if got := Build(dist).Flags; !slices.Contains(got, "-pgo=../default.pgo") {
+			t.Errorf("Build(%s).Flags = %v, want -pgo=../default.pgo", dist.FullName, got)
+		}
+	}
+}
+
+func TestReproducible(t *testing.T) {
+	profile := Profile{
+		Goos:          []string{"linux"},
+		Architectures: []Architecture{{Goarch: "amd64", Fips: true}},
+		IncludeConfig: true,
+	}
+
+	for _, fips := range []bool{false, true} {
+		dist := NewDistribution("dist", fips, profile)
+
+		build := Build(dist)
+		if build.ModTimestamp != CommitTimestamp {
+			t.Errorf("Build(fips=%v).ModTimestamp = %q, want %q", fips, build.ModTimestamp, CommitTimestamp)
+		}
+		if !slices.Contains(build.Flags, "-buildvcs=false") || !slices.Contains(build.Env, "SOURCE_DATE_EPOCH="+CommitTimestamp) {
+			t.Errorf("Build(fips=%v) = flags %v, env %v, want -buildvcs=false and SOURCE_DATE_EPOCH", fips, build.Flags, build.Env)
+		}
+		if slices.ContainsFunc(build.Ldflags, func(flag string) bool { return strings.Contains(flag, "{{ .Date }}") }) {
+			t.Errorf("Build(fips=%v).Ldflags = %v, want no build time", fips, build.Ldflags)
+		}
+
+		archive := Archive(dist)
+		if archive.BuildsInfo.MTime != CommitDate || archive.Files[0].Info.MTime != CommitDate {
+			t.Errorf("Archive(fips=%v) = %+v, want files dated %q", fips, archive, CommitDate)
+		}
+
+		pkg := Package(dist)
+		if pkg.MTime != CommitDate {
+			t.Errorf("Package(fips=%v).MTime = %q, want %q", fips, pkg.MTime, CommitDate)
+		}
+		for _, content := range pkg.Contents {
+			if content.FileInfo.MTime != CommitDate {
+				t.Errorf("Package(fips=%v) content %s has mtime %q, want %q", fips, content.Destination, content.FileInfo.MTime, CommitDate)
+			}
+		}
+	}
+}
+
+func TestPackage_Fips(t *testing.T) {
+	profile := Profile{
+		Goos:          []string{"linux"},
+		Architectures: []Architecture{{Goarch: "amd64", Fips: true}},
+		Artifacts:     Artifacts{Packages: true},
+	}
+
+	pkg := Package(NewDistribution("dist", false, profile))
+	if len(pkg.Conflicts) != 0 || pkg.Scripts.PostInstall != "postinstall.sh" {
+		t.Errorf("Package() = conflicts %v, scripts %+v, want no conflicts and the standard scripts", pkg.Conflicts, pkg.Scripts)
+	}
+
+	fips := Package(NewDistribution("dist", true, profile))
+	if fips.PackageName != "dist-fips" {
+		t.Errorf("FIPS Package().PackageName = %q, want dist-fips", fips.PackageName)
+	}
+	if want := []string{"dist", "dist-fips-native"}; !slices.Equal(fips.Conflicts, want) {
+		t.Errorf("FIPS Package().Conflicts = %v, want %v", fips.Conflicts, want)
+	}
+	if fips.Contents[0].Destination != "/lib/systemd/system/dist-fips.service" {
+		t.Errorf("FIPS Package() installs %s, want its own service unit", fips.Contents[0].Destination)
+	}
+	scripts := []string{fips.Scripts.PreInstall, fips.Scripts.PostInstall, fips.Scripts.PreRemove, fips.Scripts.PostRemove, fips.RPM.Scripts.PostTrans}
+	if want := []string{"preinstall-fips.sh", "postinstall-fips.sh", "preremove-fips.sh", "postremove-fips.sh", "posttrans-fips.sh"}; !slices.Equal(scripts, want) {
+		t.Errorf("FIPS Package().Scripts = %v, want %v", scripts, want)
+	}
+}
+
+func TestPackage_Formats(t *testing.T) {
+	profile := Profile{
+		Goos:          []string{"linux"},
+		Architectures: []Architecture{{Goarch: "amd64"}},
+		Artifacts:     Artifacts{Packages: true},
+		Packages: LinuxPackages{
+			Formats: []string{"deb", "apk", "archlinux"},
+			Overrides: map[string]PackageOverride{
+				"rpm": {Dependencies: []string{"/bin/sh"}},
+				"apk": {Dependencies: []string{"openrc"}, Scripts: PackageScripts{PreInstall: "preinstall-apk.sh", PostUpgrade: "postupgrade-apk.sh"}},
+			},
+		},
+	}
+	dist := NewDistribution("dist", false, profile)
+	dist.Dir = "dist"
+
+	pkg := Package(dist)
+	if !slices.Equal(pkg.Formats, []string{"deb", "apk", "archlinux"}) {
+		t.Errorf("Package().Formats = %v, want deb, apk and archlinux", pkg.Formats)
+	}
+	if _, ok := pkg.Overrides["rpm"]; ok {
+		t.Error("Package() overrides rpm, which isn't built")
+	}
+	apk := pkg.Overrides["apk"]
+	if !slices.Equal(apk.Dependencies, []string{"openrc"}) || apk.Scripts.PreInstall != "dist/preinstall-apk.sh" || apk.Scripts.PostInstall != "" {
+		t.Errorf("Package().Overrides[apk] = %+v, want the openrc dependency and preinstall script", apk)
+	}
+	if apk.APK.Scripts.PostUpgrade != "dist/postupgrade-apk.sh" || apk.Scripts.PostRemove != "" {
+		t.Errorf("Package().Overrides[apk].APK.Scripts = %+v, want the postupgrade script", apk.APK.Scripts)
+	}
+	if pkg.APK.Signature.KeyFile != "{{ .Env.APK_KEY_PATH }}" || pkg.APK.Signature.KeyName != APKKeyName {
+		t.Errorf("Package().APK.Signature = %+v, want the apk key", pkg.APK.Signature)
+	}
+	if pkg.ArchLinux.Packager == "" {
+		t.Error("Package() has no archlinux packager")
+	}
+
+	packagers := make(map[string][]string)
+	for _, content := range pkg.Contents {
+		packagers[content.Destination] = append(packagers[content.Destination], content.Packager)
+	}
+	if got := packagers["/lib/systemd/system/dist.service"]; !slices.Equal(got, []string{"deb", "archlinux"}) {
+		t.Errorf("Package() installs the systemd unit for %v, want deb and archlinux", got)
+	}
+	if got := packagers["/etc/init.d/dist"]; !slices.Equal(got, []string{"apk"}) {
+		t.Errorf("Package() installs the OpenRC script for %v, want apk", got)
+	}
+	if got := packagers["/etc/dist/dist.conf"]; !slices.Equal(got, []string{""}) {
+		t.Errorf("Package() installs the environment file for %v, want every format", got)
+	}
+
+	subjects := provenanceSubjects(dist)
+	if !slices.Contains(subjects, "dist_*.apk") || !slices.Contains(subjects, "dist_*.pkg.tar.zst") || slices.Contains(subjects, "dist_*.rpm") {
+		t.Errorf("provenanceSubjects() = %v, want the apk and archlinux packages but no rpm", subjects)
+	}
+}
+
+func TestDockerImages_DebugFlavor(t *testing.T) {
+	profile := Profile{
+		Goos:          []string{"linux"},
+		Architectures: []Architecture{{Goarch: "amd64", Fips: true, Images: true}, {Goarch: "arm64", Fips: true, Images: true}},
+		Artifacts:     Artifacts{Images: true, DebugImages: true},
+		Registries:    []Registry{{Address: "docker.io/newrelic"}},
+	}
+
+	dist := NewDistribution("dist", false, profile)
+	var dockerfiles []string
+	for _, image := range DockerImages(dist) {
+		dockerfiles = append(dockerfiles, image.ID+"="+image.Dockerfile)
+	}
+	want := []string{"dist-amd64=Dockerfile", "dist-arm64=Dockerfile", "dist-debug-amd64=Dockerfile.debug", "dist-debug-arm64=Dockerfile.debug"}
+	if !slices.Equal(dockerfiles, want) {
+		t.Errorf("DockerImages() = %v, want %v", dockerfiles, want)
+	}
+
+	var manifests []string
+	for _, manifest := range DockerManifests(dist) {
+		manifests = append(manifests, manifest.NameTemplate)
+	}
+	if !slices.Contains(manifests, "docker.io/newrelic/dist:{{ .Version }}-debug") {
+		t.Errorf("DockerManifests() = %v, want a debug manifest", manifests)
+	}
+	if slices.ContainsFunc(manifests, func(name string) bool {
+		return strings.Contains(name, "latest-debug") || strings.Count(name, "latest") > 1
+	}) {
+		t.Errorf("DockerManifests() = %v, want a single latest tag", manifests)
+	}
+
+	var tags []string
+	for _, tag := range DockerImageTags(NewDistribution("dist", true, profile), profile.Registries[0], ImageFlavorDebug) {
+		tags = append(tags, tag.Name)
+	}
+	if want := []string{"{{ .Version }}-fips-debug", "{{ .Major }}.{{ .Minor }}-fips-debug", "{{ .Major }}-fips-debug"}; !slices.Equal(tags, want) {
+		t.Errorf("FIPS debug DockerImageTags() = %v, want %v", tags, want)
+	}
+}
+
+func TestDockerImage_UBIFlavor(t *testing.T) {
+	profile := Profile{
+		Goos:          []string{"linux"},
+		Architectures: []Architecture{{Goarch: "amd64", Images: true}},
+		IncludeConfig: true,
+		Artifacts:     Artifacts{Images: true, UBIImages: true},
+		Registries:    []Registry{{Address: "docker.io/newrelic"}},
+	}
+	dist := NewDistribution("dist", false, profile)
+	dist.Dir = "dist"
+
+	image := DockerImage(dist, ImageFlavorUBI, "amd64")
+	if image.ID != "dist-ubi-amd64" || image.Dockerfile != "dist/Dockerfile.ubi" {
+		t.Errorf("DockerImage() = %s from %s, want dist-ubi-amd64 from dist/Dockerfile.ubi", image.ID, image.Dockerfile)
+	}
+	if want := []string{"dist/config.yaml", "dist/LICENSE", "dist/THIRD_PARTY_NOTICES.md"}; !slices.Equal(image.Files, want) {
+		t.Errorf("DockerImage().Files = %v, want %v", image.Files, want)
+	}
+	for _, flag := range []string{"--label=vendor=New Relic", "--label=io.k8s.description=NRDOT Collector - dist", "--build-arg=LICENSE_FILE=dist/LICENSE"} {
+		if !slices.Contains(image.BuildFlagTemplates, flag) {
+			t.Errorf("DockerImage().BuildFlagTemplates = %v, missing %s", image.BuildFlagTemplates, flag)
+		}
+	}
+	if slices.ContainsFunc(image.ImageTemplates, func(name string) bool { return strings.Contains(name, "latest") }) {
+		t.Errorf("DockerImage().ImageTemplates = %v, want no latest tag", image.ImageTemplates)
+	}
+
+	if slices.ContainsFunc(DockerImage(dist, ImageFlavorDefault, "amd64").BuildFlagTemplates, func(flag string) bool { return strings.HasPrefix(flag, "--label=vendor=") }) {
+		t.Error("DockerImage() sets the UBI labels on the default flavor")
+	}
+}
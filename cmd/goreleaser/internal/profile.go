@@ -0,0 +1,501 @@
+// Copyright New Relic, Inc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+
+	"github.com/goreleaser/goreleaser-pro/v2/pkg/config"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// ProfileFile is the name of the release profile inside a distribution directory.
+	ProfileFile = "release.yaml"
+	// fipsSection holds the overrides applied to a profile for the FIPS variant.
+	// Distributions without it cannot be built in FIPS mode.
+	fipsSection = "fips"
+)
+
+// Tags an image can be published with in a registry: the release version,
+// e.g. 2.3.1, its major.minor (2.3) and major (2) versions, and latest.
+const (
+	TagVersion    = "version"
+	TagMajorMinor = "major_minor"
+	TagMajor      = "major"
+	TagLatest     = "latest"
+)
+
+var (
+	supportedGoos   = []string{"linux", "windows"}
+	supportedGoarch = []string{"amd64", "arm64", "ppc64le", "s390x"}
+	// windowsGoarch are the architectures Go supports on windows.
+	windowsGoarch = []string{"amd64", "arm64"}
+	supportedTags = []string{TagVersion, TagMajorMinor, TagMajor, TagLatest}
+	// supportedPackageFormats are the nfpm packagers a distribution can ship.
+	// apk installs an OpenRC service, the others a systemd unit.
+	supportedPackageFormats = []string{"deb", "rpm", "apk", "archlinux"}
+	// capabilityPattern matches the names of Linux capabilities.
+	capabilityPattern = regexp.MustCompile(`^CAP_[A-Z_]+$`)
+	// defaultPackageFormats are built when a profile doesn't list formats.
+	defaultPackageFormats = []string{"deb", "rpm"}
+	// supportedBlobProviders maps the blob storage providers to whether they
+	// support ACLs.
+	supportedBlobProviders = map[string]bool{"s3": true, "gs": true, "azblob": false}
+
+	// DefaultTags is the tag policy of registries that don't declare one.
+	DefaultTags = supportedTags
+)
+
+// Profile describes how a distribution is released: what it is built for and
+// which artifacts are produced. Every distribution directory carries one in
+// its release.yaml.
+type Profile struct {
+	Goos          []string              `yaml:"goos"`
+	Architectures []Architecture        `yaml:"architectures"`
+	Ignore        []config.IgnoredBuild `yaml:"ignore"`
+	IncludeConfig bool                  `yaml:"include_config"`
+	Artifacts     Artifacts             `yaml:"artifacts"`
+	Packages      LinuxPackages         `yaml:"packages"`
+	Registries    []Registry            `yaml:"registries"`
+	ImageSigning  ImageSigning          `yaml:"image_signing"`
+	BlobStorage   []BlobTarget          `yaml:"blob_storage"`
+}
+
+// Architecture is a target architecture of a distribution along with what
+// it supports.
+type Architecture struct {
+	Goarch string `yaml:"goarch"`
+	// CC and CXX are the C cross-compilers of CGO builds.
+	CC  string `yaml:"cc"`
+	CXX string `yaml:"cxx"`
+	// Fips builds the architecture for the BoringCrypto FIPS variant, which
+	// requires CGO and the boringcrypto toolchain. The native FIPS variant
+	// doesn't use CGO and is built for every architecture.
+	Fips   bool `yaml:"fips"`
+	Images bool `yaml:"images"`
+}
+
+// architectures lists the architectures built for the variant using the
+// given FIPS module.
+func (p Profile) architectures(module FipsModule) []Architecture {
+	if module != FipsModuleBoringCrypto {
+		return p.Architectures
+	}
+	var r []Architecture
+	for _, arch := range p.Architectures {
+		if arch.Fips {
+			r = append(r, arch)
+		}
+	}
+	return r
+}
+
+// Artifacts toggles the kinds of artifacts produced for a distribution.
+type Artifacts struct {
+	Archives  bool `yaml:"archives"`
+	Packages  bool `yaml:"packages"`
+	MSI       bool `yaml:"msi"`
+	Images    bool `yaml:"images"`
+	Blobs     bool `yaml:"blobs"`
+	Checksums bool `yaml:"checksums"`
+	Signing   bool `yaml:"signing"`
+	// DebugSymbols adds archives of unstripped binaries, only uploaded to
+	// blob storage.
+	DebugSymbols bool `yaml:"debug_symbols"`
+	// DebugImages adds a debug flavor of the images, with a shell and
+	// network tools, tagged with a -debug suffix.
+	DebugImages bool `yaml:"debug_images"`
+	// UBIImages adds a flavor of the images based on Red Hat's Universal Base
+	// Image for OpenShift, tagged with a -ubi suffix.
+	UBIImages bool `yaml:"ubi_images"`
+}
+
+// LinuxPackages configures the Linux packages of a distribution.
+type LinuxPackages struct {
+	Formats []string `yaml:"formats"`
+	// Overrides customize the package of a format, e.g. the dependencies or
+	// the scripts of the apk package, which manage an OpenRC service.
+	Overrides map[string]PackageOverride `yaml:"overrides"`
+	// Capabilities are granted to the systemd service installed with
+	// NRDOT_MODE=CAPABILITIES, defaultCapabilities if none are set.
+	Capabilities []string `yaml:"capabilities"`
+}
+
+// capabilities lists the capabilities of the CAPABILITIES mode.
+func (p LinuxPackages) capabilities() []string {
+	if len(p.Capabilities) == 0 {
+		return defaultCapabilities
+	}
+	return p.Capabilities
+}
+
+// formats lists the package formats built, defaultPackageFormats if none
+// are set.
+func (p LinuxPackages) formats() []string {
+	if len(p.Formats) == 0 {
+		return defaultPackageFormats
+	}
+	return p.Formats
+}
+
+// PackageOverride customizes the package of a single format. Scripts are
+// files of the distribution directory replacing the default ones.
+type PackageOverride struct {
+	Dependencies []string       `yaml:"dependencies"`
+	Scripts      PackageScripts `yaml:"scripts"`
+}
+
+// PackageScripts are the scripts run by the package manager. apk and
+// archlinux run the upgrade scripts instead of the install and remove ones
+// on upgrades, other formats have none.
+type PackageScripts struct {
+	PreInstall  string `yaml:"preinstall"`
+	PostInstall string `yaml:"postinstall"`
+	PreRemove   string `yaml:"preremove"`
+	PostRemove  string `yaml:"postremove"`
+	PreUpgrade  string `yaml:"preupgrade"`
+	PostUpgrade string `yaml:"postupgrade"`
+}
+
+// upgradeScriptFormats are the package formats with upgrade scripts.
+var upgradeScriptFormats = []string{"apk", "archlinux"}
+
+// files lists the scripts that are set.
+func (s PackageScripts) files() []string {
+	var files []string
+	for _, file := range []string{s.PreInstall, s.PostInstall, s.PreRemove, s.PostRemove, s.PreUpgrade, s.PostUpgrade} {
+		if file != "" {
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
+// ImageSigning configures cosign signatures and attestations of the
+// distribution's images and manifests.
+type ImageSigning struct {
+	Enabled bool `yaml:"enabled"`
+	// Keyless signs with a certificate issued for the CI's OIDC identity
+	// instead of a private key.
+	Keyless bool `yaml:"keyless"`
+}
+
+// BlobTarget is a bucket release artifacts are uploaded to. Prefix is a
+// goreleaser template of the directory inside the bucket, in which
+// DistributionPlaceholder stands for the distribution's name.
+type BlobTarget struct {
+	Provider string `yaml:"provider"`
+	Endpoint string `yaml:"endpoint"` // S3-compatible endpoint, e.g. MinIO
+	Region   string `yaml:"region"`
+	Bucket   string `yaml:"bucket"`
+	Prefix   string `yaml:"prefix"`
+	ACL      string `yaml:"acl"`
+}
+
+// Registry is a container registry the distribution's images are pushed to,
+// along with the tags images are published with there.
+type Registry struct {
+	Address string   `yaml:"address"`
+	Tags    []string `yaml:"tags"`
+}
+
+// LoadProfile reads the release profile of the distribution in dir. When fips
+// is set, the profile's fips overrides are applied to the result.
+func LoadProfile(dir string, fips bool) (Profile, error) {
+	file := filepath.Join(dir, ProfileFile)
+
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return Profile{}, fmt.Errorf("failed to read release profile: %w", err)
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(b, &root); err != nil {
+		return Profile{}, fmt.Errorf("failed to parse %s: %w", file, err)
+	}
+	if len(root.Content) != 1 || root.Content[0].Kind != yaml.MappingNode {
+		return Profile{}, fmt.Errorf("%s: release profile must be a mapping", file)
+	}
+	overrides := removeKey(root.Content[0], fipsSection)
+
+	var profile Profile
+	if err := decodeStrict(&root, &profile); err != nil {
+		return Profile{}, fmt.Errorf("failed to parse %s: %w", file, err)
+	}
+
+	if fips {
+		if overrides == nil {
+			return Profile{}, fmt.Errorf("%s: distribution does not allow a FIPS variant", file)
+		}
+		if err := decodeStrict(overrides, &profile); err != nil {
+			return Profile{}, fmt.Errorf("failed to parse %s section of %s: %w", fipsSection, file, err)
+		}
+	}
+
+	return profile, nil
+}
+
+// removeKey deletes key from the mapping node and returns its value, or nil
+// if the key isn't present.
+func removeKey(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			value := mapping.Content[i+1]
+			mapping.Content = append(mapping.Content[:i], mapping.Content[i+2:]...)
+			return value
+		}
+	}
+	return nil
+}
+
+// decodeStrict decodes node into out, rejecting unknown fields so that typos
+// in a profile don't silently fall back to defaults.
+func decodeStrict(node *yaml.Node, out any) error {
+	b, err := yaml.Marshal(node)
+	if err != nil {
+		return err
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	dec.KnownFields(true)
+	return dec.Decode(out)
+}
+
+// Validate checks the profile for consistency and makes sure every file the
+// generated config references exists in the distribution directory.
+func (p Profile) Validate(dist Distribution, dir string) error {
+	var errs []error
+
+	if len(p.Goos) == 0 {
+		errs = append(errs, errors.New("goos must not be empty"))
+	}
+	for _, goos := range p.Goos {
+		if !slices.Contains(supportedGoos, goos) {
+			errs = append(errs, fmt.Errorf("goos %q is not supported, must be one of %v", goos, supportedGoos))
+		}
+	}
+	errs = append(errs, p.validateArchitectures(dist)...)
+
+	if p.Artifacts.Packages && !slices.Contains(p.Goos, "linux") {
+		errs = append(errs, errors.New("packages require linux builds"))
+	}
+	errs = append(errs, p.Packages.validate()...)
+	if p.Artifacts.Images {
+		if !slices.Contains(p.Goos, "linux") {
+			errs = append(errs, errors.New("images require linux builds"))
+		}
+		if len(dist.ImageGoarch()) == 0 {
+			errs = append(errs, errors.New("images require at least one architecture with images"))
+		}
+		if len(p.Registries) == 0 {
+			errs = append(errs, errors.New("images require at least one registry"))
+		}
+	}
+	if p.Artifacts.Blobs && len(p.BlobStorage) == 0 {
+		errs = append(errs, errors.New("blobs require at least one blob storage target"))
+	}
+	if p.Artifacts.DebugSymbols && !p.Artifacts.Blobs {
+		errs = append(errs, errors.New("debug symbols require blobs"))
+	}
+	for i, target := range p.BlobStorage {
+		errs = append(errs, target.validate(i))
+	}
+	if p.Artifacts.DebugImages && !p.Artifacts.Images {
+		errs = append(errs, errors.New("debug images require images"))
+	}
+	if p.Artifacts.UBIImages && !p.Artifacts.Images {
+		errs = append(errs, errors.New("ubi images require images"))
+	}
+	if p.ImageSigning.Enabled && !p.Artifacts.Images {
+		errs = append(errs, errors.New("image signing requires images"))
+	}
+	addresses := make(map[string]bool)
+	for i, registry := range p.Registries {
+		if registry.Address == "" {
+			errs = append(errs, fmt.Errorf("registry at index %d has no address", i))
+		} else if addresses[registry.Address] {
+			errs = append(errs, fmt.Errorf("registry %s is declared more than once", registry.Address))
+		}
+		addresses[registry.Address] = true
+
+		for _, tag := range registry.Tags {
+			if !slices.Contains(supportedTags, tag) {
+				errs = append(errs, fmt.Errorf("registry %s: tag %q is not supported, must be one of %v", registry.Address, tag, supportedTags))
+			}
+		}
+		if p.Artifacts.Images && len(DockerImageTags(dist, registry, ImageFlavorDefault)) == 0 {
+			errs = append(errs, fmt.Errorf("registry %s has no tags for %s", registry.Address, dist.FullName))
+		}
+	}
+	if p.Artifacts.MSI {
+		if !slices.Contains(p.Goos, "windows") {
+			errs = append(errs, errors.New("msi requires windows builds"))
+		}
+		if !p.IncludeConfig {
+			errs = append(errs, errors.New("msi requires include_config"))
+		}
+	}
+
+	if dist.Fips && !slices.Equal(p.Goos, []string{"linux"}) {
+		errs = append(errs, errors.New("fips variant can only be built for linux"))
+	}
+
+	for _, file := range requiredFiles(dist) {
+		if _, err := os.Stat(filepath.Join(dir, file)); err != nil {
+			errs = append(errs, fmt.Errorf("required file %s is missing", file))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (p Profile) validateArchitectures(dist Distribution) []error {
+	var errs []error
+
+	if len(p.Architectures) == 0 {
+		errs = append(errs, errors.New("architectures must not be empty"))
+	}
+	seen := make(map[string]bool)
+	for _, arch := range p.Architectures {
+		if !slices.Contains(supportedGoarch, arch.Goarch) {
+			errs = append(errs, fmt.Errorf("goarch %q is not supported, must be one of %v", arch.Goarch, supportedGoarch))
+		}
+		if seen[arch.Goarch] {
+			errs = append(errs, fmt.Errorf("architecture %s is declared more than once", arch.Goarch))
+		}
+		seen[arch.Goarch] = true
+
+		if arch.Fips && (arch.CC == "" || arch.CXX == "") {
+			errs = append(errs, fmt.Errorf("architecture %s supports fips but has no cc or cxx", arch.Goarch))
+		}
+	}
+	if dist.FipsModule == FipsModuleBoringCrypto && len(dist.Architectures) == 0 {
+		errs = append(errs, errors.New("fips variant has no architecture supporting fips"))
+	}
+
+	goarch := dist.Goarch()
+	for _, ignore := range p.Ignore {
+		if !slices.Contains(p.Goos, ignore.Goos) || !slices.Contains(goarch, ignore.Goarch) {
+			errs = append(errs, fmt.Errorf("ignored build %s/%s is not part of the build matrix", ignore.Goos, ignore.Goarch))
+		}
+	}
+	if slices.Contains(p.Goos, "windows") {
+		for _, arch := range goarch {
+			ignored := slices.Contains(p.Ignore, config.IgnoredBuild{Goos: "windows", Goarch: arch})
+			if !slices.Contains(windowsGoarch, arch) && !ignored {
+				errs = append(errs, fmt.Errorf("windows/%s is not supported and must be ignored", arch))
+			}
+		}
+	}
+
+	return errs
+}
+
+func (p LinuxPackages) validate() []error {
+	var errs []error
+
+	seen := make(map[string]bool)
+	for _, format := range p.Formats {
+		if !slices.Contains(supportedPackageFormats, format) {
+			errs = append(errs, fmt.Errorf("package format %q is not supported, must be one of %v", format, supportedPackageFormats))
+		}
+		if seen[format] {
+			errs = append(errs, fmt.Errorf("package format %s is declared more than once", format))
+		}
+		seen[format] = true
+	}
+	for _, capability := range p.Capabilities {
+		if !capabilityPattern.MatchString(capability) {
+			errs = append(errs, fmt.Errorf("capability %q is not a capability name, e.g. CAP_SYS_PTRACE", capability))
+		}
+	}
+	// Overrides of formats that aren't built are allowed, so that the fips
+	// section can narrow down the formats without redeclaring the overrides.
+	for format, override := range p.Overrides {
+		if !slices.Contains(supportedPackageFormats, format) {
+			errs = append(errs, fmt.Errorf("package override %q is not a supported format, must be one of %v", format, supportedPackageFormats))
+		}
+		if (override.Scripts.PreUpgrade != "" || override.Scripts.PostUpgrade != "") && !slices.Contains(upgradeScriptFormats, format) {
+			errs = append(errs, fmt.Errorf("package override %q has upgrade scripts, only %v run them", format, upgradeScriptFormats))
+		}
+	}
+
+	return errs
+}
+
+func (t BlobTarget) validate(index int) error {
+	var errs []error
+
+	acl, ok := supportedBlobProviders[t.Provider]
+	if !ok {
+		errs = append(errs, fmt.Errorf("provider %q is not supported, must be one of s3, gs or azblob", t.Provider))
+	}
+	if t.Bucket == "" {
+		errs = append(errs, errors.New("bucket must not be empty"))
+	}
+	if t.Provider == "s3" && t.Region == "" && t.Endpoint == "" {
+		errs = append(errs, errors.New("s3 requires a region or an endpoint"))
+	}
+	if t.Endpoint != "" && t.Provider != "s3" {
+		errs = append(errs, errors.New("endpoint is only supported by s3"))
+	}
+	if t.ACL != "" && ok && !acl {
+		errs = append(errs, fmt.Errorf("acl is not supported by %s", t.Provider))
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("blob storage target at index %d: %w", index, err)
+	}
+	return nil
+}
+
+// requiredFiles lists the files, relative to the distribution directory,
+// that the generated config for dist refers to.
+func requiredFiles(dist Distribution) []string {
+	files := []string{ManifestFile}
+
+	if dist.IncludeConfig {
+		files = append(files, ConfigFile)
+	}
+	if !dist.SkipPackages {
+		files = append(files,
+			EnvironmentFileTemplate(dist),
+		)
+		for _, script := range packageScripts {
+			files = append(files, PackageScriptTemplate(script))
+		}
+		if len(systemdFormats(dist)) > 0 {
+			files = append(files, UnitTemplate(dist))
+		}
+		if slices.Contains(dist.PackageFormats, "apk") {
+			files = append(files, OpenRCScript(dist))
+		}
+		for _, format := range dist.PackageFormats {
+			for _, script := range dist.PackageOverrides[format].Scripts.files() {
+				if !slices.Contains(files, script) {
+					files = append(files, script)
+				}
+			}
+		}
+	}
+	if !dist.SkipMSI {
+		files = append(files, MSIWxsFile)
+	}
+	if !dist.SkipImages {
+		for _, flavor := range dist.ImageFlavors() {
+			files = append(files, flavor.Dockerfile())
+		}
+		if !dist.SkipUBIImages {
+			files = append(files, LicenseFile, NoticeFile)
+		}
+	}
+
+	return files
+}
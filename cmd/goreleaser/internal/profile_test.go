@@ -0,0 +1,253 @@
+// Copyright New Relic, Inc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestProfileValidate_BlobStorage(t *testing.T) {
+	tests := []struct {
+		name    string
+		targets []BlobTarget
+		wantErr string
+	}{
+		{
+			name:    "s3",
+			targets: []BlobTarget{{Provider: "s3", Region: "us-east-1", Bucket: "nr-releases"}},
+		},
+		{
+			name:    "minio and azure",
+			targets: []BlobTarget{{Provider: "s3", Endpoint: "https://minio.example.com", Bucket: "releases"}, {Provider: "azblob", Bucket: "releases"}},
+		},
+		{
+			name:    "no targets",
+			wantErr: "blobs require at least one blob storage target",
+		},
+		{
+			name:    "unknown provider",
+			targets: []BlobTarget{{Provider: "ftp", Bucket: "releases"}},
+			wantErr: `provider "ftp" is not supported`,
+		},
+		{
+			name:    "missing bucket",
+			targets: []BlobTarget{{Provider: "gs"}},
+			wantErr: "bucket must not be empty",
+		},
+		{
+			name:    "s3 without region",
+			targets: []BlobTarget{{Provider: "s3", Bucket: "releases"}},
+			wantErr: "s3 requires a region or an endpoint",
+		},
+		{
+			name:    "endpoint outside s3",
+			targets: []BlobTarget{{Provider: "gs", Bucket: "releases", Endpoint: "https://example.com"}},
+			wantErr: "endpoint is only supported by s3",
+		},
+		{
+			name:    "azure acl",
+			targets: []BlobTarget{{Provider: "azblob", Bucket: "releases", ACL: "public-read"}},
+			wantErr: "blob storage target at index 0: acl is not supported by azblob",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profile := Profile{
+				Goos:          []string{"linux"},
+				Architectures: []Architecture{{Goarch: "amd64"}},
+				Artifacts:     Artifacts{Archives: true, Blobs: true},
+				BlobStorage:   tt.targets,
+			}
+			dist := NewDistribution("dist", false, profile)
+
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, ManifestFile), nil, 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			err := profile.Validate(dist, dir)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() = %v, want no error", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestProfileValidate_Architectures(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile Profile
+		fips    bool
+		wantErr string
+	}{
+		{
+			name:    "unsupported goarch",
+			profile: Profile{Goos: []string{"linux"}, Architectures: []Architecture{{Goarch: "mips"}}},
+			wantErr: `goarch "mips" is not supported`,
+		},
+		{
+			name:    "duplicate goarch",
+			profile: Profile{Goos: []string{"linux"}, Architectures: []Architecture{{Goarch: "amd64"}, {Goarch: "amd64"}}},
+			wantErr: "architecture amd64 is declared more than once",
+		},
+		{
+			name:    "fips without cross-compiler",
+			profile: Profile{Goos: []string{"linux"}, Architectures: []Architecture{{Goarch: "arm64", Fips: true}}},
+			wantErr: "architecture arm64 supports fips but has no cc or cxx",
+		},
+		{
+			name:    "fips variant without fips architectures",
+			profile: Profile{Goos: []string{"linux"}, Architectures: []Architecture{{Goarch: "s390x"}}},
+			fips:    true,
+			wantErr: "fips variant has no architecture supporting fips",
+		},
+		{
+			name:    "windows on s390x",
+			profile: Profile{Goos: []string{"linux", "windows"}, Architectures: []Architecture{{Goarch: "amd64"}, {Goarch: "s390x"}}},
+			wantErr: "windows/s390x is not supported and must be ignored",
+		},
+		{
+			name: "images without image architectures",
+			profile: Profile{
+				Goos:          []string{"linux"},
+				Architectures: []Architecture{{Goarch: "s390x"}},
+				Artifacts:     Artifacts{Images: true},
+				Registries:    []Registry{{Address: "registry.example.com"}},
+			},
+			wantErr: "images require at least one architecture with images",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dist := NewDistribution("dist", tt.fips, tt.profile)
+			err := tt.profile.Validate(dist, t.TempDir())
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestProfileValidate_FipsNative(t *testing.T) {
+	// the native module doesn't use CGO, architectures don't have to support fips
+	profile := Profile{Goos: []string{"linux"}, Architectures: []Architecture{{Goarch: "s390x"}}}
+	dist := NewFipsDistribution("dist", FipsModuleNative, profile)
+
+	err := profile.Validate(dist, t.TempDir())
+	if unwanted := "no architecture supporting fips"; err != nil && strings.Contains(err.Error(), unwanted) {
+		t.Errorf("Validate() = %v, want no error containing %q", err, unwanted)
+	}
+}
+
+func TestProfileValidate_DebugSymbols(t *testing.T) {
+	profile := Profile{
+		Goos:          []string{"linux"},
+		Architectures: []Architecture{{Goarch: "amd64"}},
+		Artifacts:     Artifacts{Archives: true, DebugSymbols: true},
+	}
+	dist := NewDistribution("dist", false, profile)
+
+	err := profile.Validate(dist, t.TempDir())
+	if want := "debug symbols require blobs"; err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("Validate() = %v, want error containing %q", err, want)
+	}
+}
+
+func TestProfileValidate_Packages(t *testing.T) {
+	tests := []struct {
+		name     string
+		packages LinuxPackages
+		files    []string
+		wantErr  string
+	}{
+		{
+			name: "default formats",
+		},
+		{
+			name: "apk",
+			packages: LinuxPackages{
+				Formats:   []string{"deb", "apk"},
+				Overrides: map[string]PackageOverride{"apk": {Scripts: PackageScripts{PostInstall: "postinstall-apk.sh"}}},
+			},
+			files: []string{"dist.openrc", "postinstall-apk.sh"},
+		},
+		{
+			name:     "apk without openrc script",
+			packages: LinuxPackages{Formats: []string{"apk"}},
+			wantErr:  "required file dist.openrc is missing",
+		},
+		{
+			name:     "missing override script",
+			packages: LinuxPackages{Formats: []string{"archlinux"}, Overrides: map[string]PackageOverride{"archlinux": {Scripts: PackageScripts{PreRemove: "preremove-arch.sh"}}}},
+			wantErr:  "required file preremove-arch.sh is missing",
+		},
+		{
+			name:     "upgrade scripts of a format without any",
+			packages: LinuxPackages{Overrides: map[string]PackageOverride{"deb": {Scripts: PackageScripts{PostUpgrade: "postupgrade-deb.sh"}}}},
+			files:    []string{"postupgrade-deb.sh"},
+			wantErr:  `package override "deb" has upgrade scripts`,
+		},
+		{
+			name:     "override of a format that isn't built",
+			packages: LinuxPackages{Overrides: map[string]PackageOverride{"apk": {Scripts: PackageScripts{PreRemove: "preremove-apk.sh"}}}},
+		},
+		{
+			name:     "unknown format",
+			packages: LinuxPackages{Formats: []string{"deb", "snap"}},
+			wantErr:  `package format "snap" is not supported`,
+		},
+		{
+			name:     "duplicate format",
+			packages: LinuxPackages{Formats: []string{"rpm", "rpm"}},
+			wantErr:  "package format rpm is declared more than once",
+		},
+		{
+			name:     "unknown override",
+			packages: LinuxPackages{Overrides: map[string]PackageOverride{"ipk": {}}},
+			wantErr:  `package override "ipk" is not a supported format`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profile := Profile{
+				Goos:          []string{"linux"},
+				Architectures: []Architecture{{Goarch: "amd64"}},
+				Artifacts:     Artifacts{Packages: true},
+				Packages:      tt.packages,
+			}
+			dist := NewDistribution("dist", false, profile)
+
+			dir := t.TempDir()
+			files := append([]string{ManifestFile, "dist.service.tmpl", "dist.conf.tmpl", "preinstall.sh.tmpl", "postinstall.sh.tmpl", "preremove.sh.tmpl", "postremove.sh.tmpl", "posttrans.sh.tmpl"}, tt.files...)
+			for _, file := range files {
+				if err := os.WriteFile(filepath.Join(dir, file), nil, 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			err := profile.Validate(dist, dir)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() = %v, want no error", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright New Relic, Inc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// packageScripts are run by deb, rpm and archlinux packages.
+var packageScripts = []string{"preinstall", "postinstall", "preremove", "postremove", "posttrans"}
+
+// PackageScriptTemplate names the template a package script is rendered
+// from in the distribution directory.
+func PackageScriptTemplate(script string) string {
+	return script + ".sh.tmpl"
+}
+
+// UnitTemplate names the template the systemd unit of every variant of dist
+// is rendered from in the distribution directory.
+func UnitTemplate(dist Distribution) string {
+	return dist.BaseName + ".service.tmpl"
+}
+
+// EnvironmentFileTemplate names the template the environment file of every
+// variant of dist is rendered from in the distribution directory.
+func EnvironmentFileTemplate(dist Distribution) string {
+	return dist.BaseName + ".conf.tmpl"
+}
+
+// packageTemplateData is what package templates are executed with.
+type packageTemplateData struct {
+	// Name is the package, service and binary name of the variant, also
+	// used for its directories.
+	Name string
+	// Fips tells FIPS variants apart.
+	Fips bool
+	// User runs the service of every variant of the distribution.
+	User string
+	// Variants lists the other packages of the distribution, which share
+	// User.
+	Variants []string
+}
+
+// PackageFiles renders the package scripts, systemd unit and environment
+// file of dist from the templates in dir, its distribution directory, keyed
+// by their file in that directory. Each is rendered for every variant from a
+// single template, so that the variants manage their own service the same
+// way. Distributions without packages have none.
+func PackageFiles(dir string, dist Distribution) (map[string][]byte, error) {
+	if dist.SkipPackages {
+		return nil, nil
+	}
+
+	data := packageTemplateData{
+		Name:     dist.FullName,
+		Fips:     dist.Fips,
+		User:     dist.BaseName,
+		Variants: otherVariants(dist),
+	}
+	render := func(name string) (string, error) {
+		tmpl, err := template.New(name).Option("missingkey=error").ParseFiles(filepath.Join(dir, name))
+		if err != nil {
+			return "", err
+		}
+		var b bytes.Buffer
+		if err := tmpl.Execute(&b, data); err != nil {
+			return "", err
+		}
+		return b.String(), nil
+	}
+	header := func(name string) string {
+		return fmt.Sprintf("# Generated by cmd/goreleaser from %s, run `make generate-goreleaser` to update.\n", name)
+	}
+
+	files := make(map[string][]byte)
+	for _, script := range packageScripts {
+		name := PackageScriptTemplate(script)
+		content, err := render(name)
+		if err != nil {
+			return nil, err
+		}
+		// keep the shebang first
+		shebang, body, _ := strings.Cut(content, "\n")
+		files[PackageScript(dist, script)] = []byte(shebang + "\n" + header(name) + body)
+	}
+
+	for file, name := range map[string]string{
+		EnvironmentFile(dist): EnvironmentFileTemplate(dist),
+		Unit(dist):            UnitTemplate(dist),
+	} {
+		if file == Unit(dist) && len(systemdFormats(dist)) == 0 {
+			continue
+		}
+		content, err := render(name)
+		if err != nil {
+			return nil, err
+		}
+		files[file] = []byte(header(name) + content)
+	}
+	return files, nil
+}
@@ -0,0 +1,117 @@
+// Copyright New Relic, Inc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// The committed package files serve as golden files, regenerate them with
+// `make generate-goreleaser` when a template changes.
+func TestPackageFiles_Golden(t *testing.T) {
+	profiles, err := filepath.Glob(filepath.Join(distsDir, "*", ProfileFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, profile := range profiles {
+		dir := filepath.Dir(profile)
+		name := filepath.Base(dir)
+		dists, err := LoadDistributions(distsDir, []string{name}, FipsBoth, FipsModuleBoringCrypto)
+		if err != nil {
+			t.Fatal(err)
+		}
+		native, err := LoadDistributions(distsDir, []string{name}, FipsOnly, FipsModuleNative)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, dist := range append(dists, native...) {
+			files, err := PackageFiles(dir, dist)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for file, want := range files {
+				got, err := os.ReadFile(filepath.Join(dir, file))
+				if err != nil {
+					t.Errorf("%s: %v, run `make generate-goreleaser`", dist.FullName, err)
+					continue
+				}
+				if string(got) != string(want) {
+					t.Errorf("%s is out of date, run `make generate-goreleaser`", filepath.Join(name, file))
+				}
+			}
+		}
+	}
+}
+
+func TestPackageFiles(t *testing.T) {
+	profile := Profile{
+		Goos:          []string{"linux"},
+		Architectures: []Architecture{{Goarch: "amd64", Fips: true}},
+		Artifacts:     Artifacts{Packages: true},
+	}
+	dir := t.TempDir()
+	templates := map[string]string{
+		UnitTemplate(NewDistribution("dist", false, profile)):            "ExecStart=/usr/bin/{{ .Name }}\nUser={{ .User }}\n",
+		EnvironmentFileTemplate(NewDistribution("dist", false, profile)): "OPTIONS=/etc/{{ .Name }}/config.yaml\n",
+	}
+	for _, script := range packageScripts {
+		templates[PackageScriptTemplate(script)] = "#!/bin/sh\nsystemctl start {{ .Name }}.service\nuser={{ .User }}\n{{ range .Variants }}other={{ . }}\n{{ end }}"
+	}
+	for file, content := range templates {
+		if err := os.WriteFile(filepath.Join(dir, file), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, tt := range []struct {
+		module  FipsModule
+		name    string
+		variant string
+	}{
+		{"", "dist", "dist-fips"},
+		{FipsModuleBoringCrypto, "dist-fips", "dist"},
+		{FipsModuleNative, "dist-fips-native", "dist-fips"},
+	} {
+		dist := NewDistribution("dist", false, profile)
+		if tt.module != "" {
+			dist = NewFipsDistribution("dist", tt.module, profile)
+		}
+		files, err := PackageFiles(dir, dist)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(files) != len(packageScripts)+2 {
+			t.Fatalf("PackageFiles(%s) = %d files, want the scripts, the unit and the environment file", tt.name, len(files))
+		}
+		if unit := string(files[tt.name+".service"]); !strings.Contains(unit, "ExecStart=/usr/bin/"+tt.name+"\nUser=dist\n") {
+			t.Errorf("%s unit = %q, want the variant's binary run as the distribution's user", tt.name, unit)
+		}
+		if conf := string(files[tt.name+".conf"]); !strings.HasPrefix(conf, "# Generated by cmd/goreleaser") || !strings.Contains(conf, "/etc/"+tt.name+"/") {
+			t.Errorf("%s environment file = %q, want the generated header and the variant's config", tt.name, conf)
+		}
+
+		postinstall := string(files[PackageScript(dist, "postinstall")])
+		if !strings.HasPrefix(postinstall, "#!/bin/sh\n# Generated by cmd/goreleaser from postinstall.sh.tmpl") {
+			t.Errorf("%s postinstall = %q, want the shebang followed by the generated header", tt.name, postinstall)
+		}
+		for _, want := range []string{"systemctl start " + tt.name + ".service\n", "user=dist\n", "other=" + tt.variant + "\n"} {
+			if !strings.Contains(postinstall, want) {
+				t.Errorf("%s postinstall = %q, want it to contain %q", tt.name, postinstall, want)
+			}
+		}
+		if strings.Contains(postinstall, "other="+tt.name+"\n") {
+			t.Errorf("%s postinstall = %q, want its own package left out of the variants", tt.name, postinstall)
+		}
+	}
+
+	profile.Artifacts.Packages = false
+	if files, err := PackageFiles(dir, NewDistribution("dist", false, profile)); err != nil || len(files) != 0 {
+		t.Errorf("PackageFiles() = %v, %v without packages, want none", files, err)
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright New Relic, Inc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Modes the systemd service of a package can be installed in through
+// NRDOT_MODE, besides the default one running as the distribution's user.
+// Each is a drop-in shipped by the package and enabled by its postinstall
+// script, the unit itself is never edited.
+const (
+	// ModeRoot runs the service as root.
+	ModeRoot = "ROOT"
+	// ModeCapabilities keeps the distribution's user, grants it the
+	// profile's capabilities and sandboxes the service.
+	ModeCapabilities = "CAPABILITIES"
+)
+
+var (
+	serviceModes = []string{ModeRoot, ModeCapabilities}
+	// defaultCapabilities let the collector read logs it doesn't own and
+	// inspect the processes of other users for host metrics.
+	defaultCapabilities = []string{"CAP_DAC_READ_SEARCH", "CAP_SYS_PTRACE"}
+)
+
+// DropInFile names the generated drop-in of a service mode in the
+// distribution directory.
+func DropInFile(dist Distribution, mode string) string {
+	return fmt.Sprintf("%s-%s.conf", dist.FullName, strings.ToLower(mode))
+}
+
+// dropInPath is where packages install the drop-in of a service mode. The
+// postinstall script links it into /etc/systemd/system/<dist>.service.d.
+func dropInPath(dist Distribution, mode string) string {
+	return path.Join("/usr", "share", dist.FullName, "systemd", strings.ToLower(mode)+".conf")
+}
+
+// DropIns renders the drop-ins of dist's service modes, keyed by their file
+// in the distribution directory. Distributions without a systemd unit have
+// none.
+func DropIns(dist Distribution) map[string][]byte {
+	if dist.SkipPackages || len(systemdFormats(dist)) == 0 {
+		return nil
+	}
+
+	dropIns := make(map[string][]byte)
+	for _, mode := range serviceModes {
+		var b strings.Builder
+		fmt.Fprintf(&b, "# Generated by cmd/goreleaser from %s, run `make generate-goreleaser` to update.\n", ProfileFile)
+		fmt.Fprintf(&b, "# Installed with NRDOT_MODE=%s as a drop-in of %s.service.\n", mode, dist.FullName)
+		b.WriteString("[Service]\n")
+
+		switch mode {
+		case ModeRoot:
+			b.WriteString("User=root\n")
+			b.WriteString("Group=root\n")
+		case ModeCapabilities:
+			capabilities := strings.Join(dist.Capabilities, " ")
+			fmt.Fprintf(&b, "AmbientCapabilities=%s\n", capabilities)
+			fmt.Fprintf(&b, "CapabilityBoundingSet=%s\n", capabilities)
+			for _, directive := range sandboxing {
+				b.WriteString(directive + "\n")
+			}
+		}
+		dropIns[DropInFile(dist, mode)] = []byte(b.String())
+	}
+	return dropIns
+}
+
+// sandboxing restricts the service in the capabilities mode. The collector
+// only writes to its state directory, which ProtectSystem=strict leaves
+// writable.
+var sandboxing = []string{
+	"NoNewPrivileges=true",
+	"ProtectSystem=strict",
+	"ProtectHome=read-only",
+	"PrivateTmp=true",
+	"PrivateDevices=true",
+	"ProtectKernelTunables=true",
+	"ProtectKernelModules=true",
+	"ProtectKernelLogs=true",
+	"ProtectControlGroups=true",
+	"ProtectClock=true",
+	"ProtectHostname=true",
+	"RestrictAddressFamilies=AF_UNIX AF_INET AF_INET6 AF_NETLINK",
+	"RestrictNamespaces=true",
+	"RestrictRealtime=true",
+	"RestrictSUIDSGID=true",
+	"LockPersonality=true",
+	"MemoryDenyWriteExecute=true",
+	"SystemCallArchitectures=native",
+}
@@ -0,0 +1,76 @@
+// Copyright New Relic, Inc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// The committed drop-ins serve as golden files, regenerate them with
+// `make generate-goreleaser` when the generator or a profile changes.
+func TestDropIns_Golden(t *testing.T) {
+	profiles, err := filepath.Glob(filepath.Join(distsDir, "*", ProfileFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, profile := range profiles {
+		name := filepath.Base(filepath.Dir(profile))
+		dists, err := LoadDistributions(distsDir, []string{name}, FipsBoth, FipsModuleBoringCrypto)
+		if err != nil {
+			t.Fatal(err)
+		}
+		native, err := LoadDistributions(distsDir, []string{name}, FipsOnly, FipsModuleNative)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, dist := range append(dists, native...) {
+			for file, want := range DropIns(dist) {
+				got, err := os.ReadFile(filepath.Join(distsDir, name, file))
+				if err != nil {
+					t.Errorf("%s: %v, run `make generate-goreleaser`", dist.FullName, err)
+					continue
+				}
+				if string(got) != string(want) {
+					t.Errorf("%s is out of date, run `make generate-goreleaser`", filepath.Join(name, file))
+				}
+			}
+		}
+	}
+}
+
+func TestDropIns(t *testing.T) {
+	profile := Profile{
+		Goos:          []string{"linux"},
+		Architectures: []Architecture{{Goarch: "amd64"}},
+		Artifacts:     Artifacts{Packages: true},
+		Packages:      LinuxPackages{Capabilities: []string{"CAP_DAC_READ_SEARCH"}},
+	}
+
+	dropIns := DropIns(NewDistribution("dist", false, profile))
+	if len(dropIns) != len(serviceModes) {
+		t.Fatalf("DropIns() = %d drop-ins, want one per service mode", len(dropIns))
+	}
+	capabilities := string(dropIns["dist-capabilities.conf"])
+	for _, want := range []string{"AmbientCapabilities=CAP_DAC_READ_SEARCH\n", "CapabilityBoundingSet=CAP_DAC_READ_SEARCH\n", "ProtectSystem=strict\n"} {
+		if !strings.Contains(capabilities, want) {
+			t.Errorf("capabilities drop-in = %q, want it to contain %q", capabilities, want)
+		}
+	}
+	if strings.Contains(capabilities, "User=") {
+		t.Errorf("capabilities drop-in = %q, want the unit's user", capabilities)
+	}
+	if root := string(dropIns["dist-root.conf"]); !strings.Contains(root, "User=root\n") {
+		t.Errorf("root drop-in = %q, want it to run as root", root)
+	}
+
+	profile.Packages.Formats = []string{"apk"}
+	if dropIns := DropIns(NewDistribution("dist", false, profile)); len(dropIns) != 0 {
+		t.Errorf("DropIns() = %v for an OpenRC service, want none", dropIns)
+	}
+}
@@ -17,16 +17,25 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"log"
 	"os"
+	"path/filepath"
+	"strings"
 
+	"github.com/goreleaser/goreleaser-pro/v2/pkg/config"
 	"gopkg.in/yaml.v3"
 
 	"github.com/newrelic/nrdot-collector-releases/cmd/goreleaser/internal"
 )
 
-var distFlag = flag.String("d", "", "Collector distributions to build")
-var fipsFlag = flag.Bool("f", false, "Whether we're building a FIPS compliant config")
+var distFlag = flag.String("d", "", "Collector distributions to build, comma-separated")
+var fipsFlag = flag.Bool("f", false, "Whether we're building a FIPS compliant config, shorthand for -fips true")
+var fipsModeFlag = flag.String("fips", string(internal.FipsNone), "FIPS variants to build: false, true or both")
+var fipsModuleFlag = flag.String("fips-module", string(internal.FipsModuleBoringCrypto), "Cryptographic module of the FIPS variant: boringcrypto or native")
+var distsDirFlag = flag.String("dir", "distributions", "Directory containing the distributions and their release profiles")
+var checkFlag = flag.String("check", "", "Compare the generated config with the goreleaser file at this path instead of printing it")
+var packageFilesFlag = flag.Bool("package-files", false, "Write the generated package files of the distributions into their directories instead of printing the config")
 
 func main() {
 	flag.Parse()
@@ -35,7 +44,32 @@ func main() {
 		log.Fatal("no distribution to build")
 	}
 
-	project := internal.Generate(*distFlag, *fipsFlag)
+	fipsMode, err := internal.ParseFipsMode(*fipsModeFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *fipsFlag {
+		fipsMode = internal.FipsOnly
+	}
+	fipsModule, err := internal.ParseFipsModule(*fipsModuleFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *packageFilesFlag {
+		writePackageFiles(*distsDirFlag, strings.Split(*distFlag, ","), fipsMode, fipsModule)
+		return
+	}
+
+	project, err := internal.Generate(*distsDirFlag, strings.Split(*distFlag, ","), fipsMode, fipsModule)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *checkFlag != "" {
+		check(*checkFlag, project)
+		return
+	}
 
 	e := yaml.NewEncoder(os.Stdout)
 	e.SetIndent(2)
@@ -43,3 +77,58 @@ func main() {
 		log.Fatal(err)
 	}
 }
+
+// check reports how the goreleaser file at path differs from project and
+// exits with a non-zero code if it does.
+func check(path string, project config.Project) {
+	committed, err := os.ReadFile(path)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	diffs, err := internal.Diff(committed, project)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(diffs) == 0 {
+		return
+	}
+
+	for _, diff := range diffs {
+		fmt.Fprintf(os.Stderr, "  %s\n", diff)
+	}
+	log.Fatalf("%s is out of date, run `make generate-goreleaser`", path)
+}
+
+// writePackageFiles writes the generated systemd drop-ins, package scripts,
+// units and environment files of each distribution into its directory, where
+// the packages pick them up.
+func writePackageFiles(distsDir string, distNames []string, fipsMode internal.FipsMode, fipsModule internal.FipsModule) {
+	dists, err := internal.LoadDistributions(distsDir, distNames, fipsMode, fipsModule)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for _, dist := range dists {
+		dir := filepath.Join(distsDir, dist.BaseName)
+		for file, content := range internal.DropIns(dist) {
+			if err := os.WriteFile(filepath.Join(dir, file), content, 0o644); err != nil {
+				log.Fatal(err)
+			}
+		}
+
+		files, err := internal.PackageFiles(dir, dist)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for file, content := range files {
+			mode := os.FileMode(0o644)
+			if strings.HasSuffix(file, ".sh") {
+				mode = 0o755
+			}
+			if err := os.WriteFile(filepath.Join(dir, file), content, mode); err != nil {
+				log.Fatal(err)
+			}
+		}
+	}
+}
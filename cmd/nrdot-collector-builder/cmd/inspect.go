@@ -0,0 +1,74 @@
+// Copyright New Relic, Inc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"newrelic-collector-builder/internal/inspect"
+
+	"github.com/spf13/cobra"
+)
+
+// inspectCmd represents the inspect command
+var inspectCmd = &cobra.Command{
+	Use:   "inspect",
+	Short: "Check the build settings of a collector binary",
+	Long: `Check the build info and symbols of a collector binary against the
+build settings of its distribution: static linking, the recorded build
+environment such as GOEXPERIMENT, the build tags and the packages linked in,
+e.g. crypto/tls/fipsonly. It is run by goreleaser after each build and writes
+a JSON report, failing if a check doesn't pass.`,
+	SilenceUsage: true,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		output, _ := cmd.Flags().GetString("output")
+
+		var opts inspect.Options
+		opts.Binary, _ = cmd.Flags().GetString("binary")
+		opts.Static, _ = cmd.Flags().GetBool("static")
+		opts.Env, _ = cmd.Flags().GetStringArray("env")
+		opts.Tags, _ = cmd.Flags().GetStringArray("tags")
+		opts.Packages, _ = cmd.Flags().GetStringArray("package")
+
+		report, err := inspect.Inspect(opts)
+		if err != nil {
+			return err
+		}
+
+		b, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal the report: %w", err)
+		}
+		if output == "" {
+			fmt.Println(string(b))
+		} else if err := os.WriteFile(output, append(b, '\n'), 0o644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", output, err)
+		}
+
+		if !report.Passed {
+			for _, check := range report.Checks {
+				if !check.Passed {
+					fmt.Fprintf(os.Stderr, "%s: %s is %s, expected %s\n", opts.Binary, check.Name, check.Actual, check.Expected)
+				}
+			}
+			return fmt.Errorf("%s doesn't match the build settings of its distribution", opts.Binary)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(inspectCmd)
+
+	inspectCmd.Flags().String("binary", "", "Path of the binary to inspect")
+	inspectCmd.Flags().String("output", "", "Path of the JSON report to write, printed if empty")
+	inspectCmd.Flags().Bool("static", false, "Whether the binary must be statically linked")
+	inspectCmd.Flags().StringArray("env", nil, "Build environment variable as KEY=VALUE (repeatable)")
+	inspectCmd.Flags().StringArray("tags", nil, "Go build tag (repeatable)")
+	inspectCmd.Flags().StringArray("package", nil, "Import path of a package that must be linked in (repeatable)")
+	_ = inspectCmd.MarkFlagRequired("binary")
+}
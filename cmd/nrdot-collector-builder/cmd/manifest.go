@@ -26,8 +26,10 @@ var manifestCmd = &cobra.Command{
 
 func init() {
 	rootCmd.AddCommand(manifestCmd)
-	// Register the update subcommand
+	// Register the update, provenance and buildinfo subcommands
 	manifestCmd.AddCommand(manifest.UpdateCmd)
+	manifestCmd.AddCommand(manifest.ProvenanceCmd)
+	manifestCmd.AddCommand(manifest.BuildInfoCmd)
 
 	// Define a persistent flag for `manifestCmd`
 	manifestCmd.PersistentFlags().StringVarP(
@@ -0,0 +1,50 @@
+// Copyright New Relic, Inc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package manifest
+
+import (
+	"fmt"
+	"os"
+
+	"newrelic-collector-builder/internal/buildinfo"
+
+	"github.com/spf13/cobra"
+)
+
+// BuildInfoCmd represents the `manifest buildinfo` subcommand
+var BuildInfoCmd = &cobra.Command{
+	Use:   "buildinfo",
+	Short: "Generate the build-info source of a distribution",
+	Long: `Generate the collector source reporting the build metadata, with the
+component versions resolved from the manifest. It is written into the OCB
+build folder, where goreleaser injects the commit, date and FIPS status.
+With --fips, the FIPS runtime status is read from the startup code copied
+from fips/ into the build folder.`,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configPath, _ := cmd.Flags().GetString("config")
+		verbose, _ := cmd.Root().PersistentFlags().GetBool("verbose")
+		output, _ := cmd.Flags().GetString("output")
+		fips, _ := cmd.Flags().GetBool("fips")
+
+		cfg, err := loadConfig(configPath, verbose)
+		if err != nil {
+			return err
+		}
+
+		src, err := buildinfo.Generate(cfg.Versions, fips)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(output, src, 0o644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", output, err)
+		}
+		return nil
+	},
+}
+
+func init() {
+	BuildInfoCmd.Flags().String("output", buildinfo.FileName, "Path of the Go source to write")
+	BuildInfoCmd.Flags().Bool("fips", false, "Whether the source is generated for a FIPS build")
+}
@@ -0,0 +1,91 @@
+// Copyright New Relic, Inc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package manifest
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"newrelic-collector-builder/internal/provenance"
+
+	"github.com/spf13/cobra"
+)
+
+// ProvenanceCmd represents the `manifest provenance` subcommand
+var ProvenanceCmd = &cobra.Command{
+	Use:   "provenance",
+	Short: "Generate the SLSA provenance of a release",
+	Long: `Generate the SLSA provenance of the files built from the manifest.
+It is run by goreleaser, which passes the build settings of the distribution.`,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configPath, _ := cmd.Flags().GetString("config")
+		verbose, _ := cmd.Root().PersistentFlags().GetBool("verbose")
+		output, _ := cmd.Flags().GetString("output")
+		predicateOutput, _ := cmd.Flags().GetString("predicate-output")
+
+		var opts provenance.Options
+		opts.ManifestPath = configPath
+		opts.Distribution, _ = cmd.Flags().GetString("distribution")
+		opts.Version, _ = cmd.Flags().GetString("version")
+		opts.Fips, _ = cmd.Flags().GetBool("fips")
+		opts.FipsModule, _ = cmd.Flags().GetString("fips-module")
+		opts.Source, _ = cmd.Flags().GetString("source")
+		opts.Commit, _ = cmd.Flags().GetString("commit")
+		opts.Env, _ = cmd.Flags().GetStringArray("env")
+		opts.Flags, _ = cmd.Flags().GetStringArray("flags")
+		opts.Ldflags, _ = cmd.Flags().GetStringArray("ldflags")
+		opts.Tags, _ = cmd.Flags().GetStringArray("tags")
+		opts.Subjects, _ = cmd.Flags().GetStringArray("subject")
+
+		cfg, err := loadConfig(configPath, verbose)
+		if err != nil {
+			return err
+		}
+		if opts.GoVersion, err = cfg.GoVersion(); err != nil {
+			return fmt.Errorf("failed to determine go version: %w", err)
+		}
+
+		statement, err := provenance.New(opts, cfg.Versions)
+		if err != nil {
+			return fmt.Errorf("failed to generate provenance: %w", err)
+		}
+
+		if err := writeJSON(output, statement); err != nil {
+			return err
+		}
+		if predicateOutput != "" {
+			return writeJSON(predicateOutput, statement.Predicate)
+		}
+		return nil
+	},
+}
+
+func init() {
+	ProvenanceCmd.Flags().String("output", "provenance.json", "Path of the in-toto statement to write")
+	ProvenanceCmd.Flags().String("predicate-output", "", "Path to also write the bare provenance predicate to, as expected by `cosign attest`")
+	ProvenanceCmd.Flags().String("distribution", "", "Name of the distribution variant")
+	ProvenanceCmd.Flags().String("version", "", "Version of the release")
+	ProvenanceCmd.Flags().Bool("fips", false, "Whether the FIPS variant is built")
+	ProvenanceCmd.Flags().String("fips-module", "", "Cryptographic module of the FIPS variant: boringcrypto or native")
+	ProvenanceCmd.Flags().String("source", "", "Git URL of the repository")
+	ProvenanceCmd.Flags().String("commit", "", "Commit the release is built from")
+	ProvenanceCmd.Flags().StringArray("env", nil, "Build environment variable as KEY=VALUE (repeatable)")
+	ProvenanceCmd.Flags().StringArray("flags", nil, "Go build flag (repeatable)")
+	ProvenanceCmd.Flags().StringArray("ldflags", nil, "Go linker flag (repeatable)")
+	ProvenanceCmd.Flags().StringArray("tags", nil, "Go build tag (repeatable)")
+	ProvenanceCmd.Flags().StringArray("subject", nil, "Glob pattern of the released files (repeatable)")
+}
+
+func writeJSON(path string, v any) error {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", path, err)
+	}
+	if err := os.WriteFile(path, append(b, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
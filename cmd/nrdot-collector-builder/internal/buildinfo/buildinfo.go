@@ -0,0 +1,46 @@
+// Copyright New Relic, Inc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package buildinfo
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"go/format"
+	"text/template"
+
+	"newrelic-collector-builder/internal/manifest"
+)
+
+// FileName is the name of the generated source in the OCB build folder.
+const FileName = "buildinfo.go"
+
+//go:embed buildinfo.go.tmpl
+var source string
+
+var tmpl = template.Must(template.New(FileName).Parse(source))
+
+// data is what the template renders.
+type data struct {
+	manifest.Versions
+	// Fips leaves fipsStatus to the FIPS startup code copied from fips/ into
+	// the build folder, so that a FIPS build without it doesn't compile.
+	Fips bool
+}
+
+// Generate renders the collector source reporting the build metadata. The
+// component versions are resolved from the manifest, while the commit, date,
+// version and FIPS status are injected by goreleaser through ldflags.
+func Generate(versions manifest.Versions, fips bool) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data{Versions: versions, Fips: fips}); err != nil {
+		return nil, fmt.Errorf("failed to render %s: %w", FileName, err)
+	}
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to format %s: %w", FileName, err)
+	}
+	return src, nil
+}
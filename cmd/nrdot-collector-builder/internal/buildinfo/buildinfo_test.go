@@ -0,0 +1,46 @@
+// Copyright New Relic, Inc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package buildinfo
+
+import (
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"newrelic-collector-builder/internal/manifest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerate(t *testing.T) {
+	src, err := Generate(manifest.Versions{
+		BetaCoreVersion:    "v0.125.0",
+		BetaContribVersion: "v0.125.0",
+		StableCoreVersion:  "v1.31.0",
+	}, false)
+	require.NoError(t, err)
+
+	file, err := parser.ParseFile(token.NewFileSet(), FileName, src, parser.ParseComments)
+	require.NoError(t, err)
+	assert.Equal(t, "main", file.Name.Name)
+
+	assert.Contains(t, string(src), `BetaCoreVersion:      "v0.125.0",`)
+	assert.Contains(t, string(src), `StableCoreVersion:    "v1.31.0",`)
+	assert.Contains(t, string(src), `NrdotVersion:         "",`)
+	assert.Contains(t, string(src), "func fipsStatus() fipsInfo {")
+}
+
+func TestGenerate_Fips(t *testing.T) {
+	src, err := Generate(manifest.Versions{BetaCoreVersion: "v0.125.0"}, true)
+	require.NoError(t, err)
+
+	_, err = parser.ParseFile(token.NewFileSet(), FileName, src, parser.ParseComments)
+	require.NoError(t, err)
+	// provided by the FIPS startup code
+	assert.NotContains(t, string(src), "func fipsStatus()")
+	assert.Contains(t, string(src), "FipsStatus:   fipsStatus(),")
+	// set at link time along with the other build metadata
+	assert.Contains(t, string(src), "FipsModule:   buildFipsModule,")
+}
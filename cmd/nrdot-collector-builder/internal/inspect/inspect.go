@@ -0,0 +1,168 @@
+// Copyright New Relic, Inc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package inspect
+
+import (
+	"debug/buildinfo"
+	"debug/elf"
+	"debug/gosym"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// recordedEnv are the build environment variables the Go toolchain records in
+// the build info of a binary, the others can't be checked.
+var recordedEnv = []string{"CGO_ENABLED", "GOEXPERIMENT", "GOFIPS140"}
+
+// Options holds the build settings a binary is expected to have been built
+// with, as configured for its distribution.
+type Options struct {
+	Binary   string
+	Static   bool     // whether the binary must not need a dynamic loader
+	Env      []string // KEY=VALUE pairs
+	Tags     []string
+	Packages []string // import paths of packages that must be linked in
+}
+
+// Report lists the checks of a binary against its expected build settings.
+type Report struct {
+	Binary    string  `json:"binary"`
+	GoVersion string  `json:"goVersion"`
+	Passed    bool    `json:"passed"`
+	Checks    []Check `json:"checks"`
+}
+
+type Check struct {
+	Name     string `json:"name"`
+	Expected string `json:"expected"`
+	Actual   string `json:"actual"`
+	Passed   bool   `json:"passed"`
+}
+
+func (r *Report) add(name, expected, actual string, passed bool) {
+	r.Checks = append(r.Checks, Check{Name: name, Expected: expected, Actual: actual, Passed: passed})
+	r.Passed = r.Passed && passed
+}
+
+// Inspect reads the build info and the symbols of opts.Binary and checks them
+// against opts. Linking and symbols are only read from ELF binaries, which all
+// FIPS builds are.
+func Inspect(opts Options) (Report, error) {
+	info, err := buildinfo.ReadFile(opts.Binary)
+	if err != nil {
+		return Report{}, fmt.Errorf("failed to read the build info of %s: %w", opts.Binary, err)
+	}
+	settings := make(map[string]string, len(info.Settings))
+	for _, setting := range info.Settings {
+		settings[setting.Key] = setting.Value
+	}
+
+	report := Report{Binary: filepath.ToSlash(opts.Binary), GoVersion: info.GoVersion, Passed: true}
+
+	for _, e := range opts.Env {
+		key, value, ok := strings.Cut(e, "=")
+		if !ok {
+			return Report{}, fmt.Errorf("invalid environment variable %q, must be KEY=VALUE", e)
+		}
+		if !slices.Contains(recordedEnv, key) {
+			continue
+		}
+		actual := settings[key]
+		report.add("env:"+key, value, actual, envMatches(key, value, actual))
+	}
+
+	tags := strings.Split(settings["-tags"], ",")
+	for _, tag := range opts.Tags {
+		report.add("tag:"+tag, "present", presence(slices.Contains(tags, tag)), slices.Contains(tags, tag))
+	}
+
+	f, err := elf.Open(opts.Binary)
+	if err != nil {
+		if len(opts.Packages) > 0 {
+			return Report{}, fmt.Errorf("failed to read the symbols of %s: %w", opts.Binary, err)
+		}
+		return report, nil
+	}
+	defer f.Close()
+
+	if opts.Static {
+		dynamic, err := dynamicallyLinked(f)
+		if err != nil {
+			return Report{}, err
+		}
+		report.add("static", "true", fmt.Sprint(!dynamic), !dynamic)
+	}
+
+	if len(opts.Packages) > 0 {
+		packages, err := linkedPackages(f)
+		if err != nil {
+			return Report{}, fmt.Errorf("failed to read the symbols of %s: %w", opts.Binary, err)
+		}
+		for _, pkg := range opts.Packages {
+			report.add("package:"+pkg, "present", presence(packages[pkg]), packages[pkg])
+		}
+	}
+
+	return report, nil
+}
+
+// envMatches compares a recorded build setting with its expected value. An
+// empty GOEXPERIMENT isn't recorded, and GOFIPS140 versions are recorded with
+// the hash of the module, e.g. v1.0.0-c2097c7c.
+func envMatches(key, expected, actual string) bool {
+	if key == "GOFIPS140" && expected != "" {
+		return actual == expected || strings.HasPrefix(actual, expected+"-")
+	}
+	return actual == expected
+}
+
+func presence(present bool) string {
+	if present {
+		return "present"
+	}
+	return "missing"
+}
+
+// dynamicallyLinked reports whether the binary needs a dynamic loader or
+// shared libraries at runtime.
+func dynamicallyLinked(f *elf.File) (bool, error) {
+	for _, prog := range f.Progs {
+		if prog.Type == elf.PT_INTERP {
+			return true, nil
+		}
+	}
+	libs, err := f.ImportedLibraries()
+	if err != nil && !errors.Is(err, elf.ErrNoSymbols) {
+		return false, fmt.Errorf("failed to read the imported libraries: %w", err)
+	}
+	return len(libs) > 0, nil
+}
+
+// linkedPackages lists the packages with functions in the binary. They are
+// read from the Go symbol table, which stripped binaries keep.
+func linkedPackages(f *elf.File) (map[string]bool, error) {
+	pclntab, text := f.Section(".gopclntab"), f.Section(".text")
+	if pclntab == nil || text == nil {
+		return nil, errors.New("no Go symbol table")
+	}
+	data, err := pclntab.Data()
+	if err != nil {
+		return nil, err
+	}
+	table, err := gosym.NewTable(nil, gosym.NewLineTable(data, text.Addr))
+	if err != nil {
+		return nil, err
+	}
+
+	packages := make(map[string]bool)
+	for _, fn := range table.Funcs {
+		if pkg := fn.PackageName(); pkg != "" {
+			packages[pkg] = true
+		}
+	}
+	return packages, nil
+}
@@ -0,0 +1,79 @@
+// Copyright New Relic, Inc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package inspect
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInspect(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("symbols are only read from ELF binaries")
+	}
+	binary, err := os.Executable()
+	require.NoError(t, err)
+
+	info, ok := debug.ReadBuildInfo()
+	require.True(t, ok)
+	var cgo string
+	for _, setting := range info.Settings {
+		if setting.Key == "CGO_ENABLED" {
+			cgo = setting.Value
+		}
+	}
+
+	report, err := Inspect(Options{
+		Binary:   binary,
+		Env:      []string{"CGO_ENABLED=" + cgo, "SOURCE_DATE_EPOCH=1700000000"},
+		Packages: []string{"testing", "crypto/tls/fipsonly"},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, runtime.Version(), report.GoVersion)
+	assert.False(t, report.Passed)
+	assert.Equal(t, []Check{
+		{Name: "env:CGO_ENABLED", Expected: cgo, Actual: cgo, Passed: true},
+		{Name: "package:testing", Expected: "present", Actual: "present", Passed: true},
+		{Name: "package:crypto/tls/fipsonly", Expected: "present", Actual: "missing", Passed: false},
+	}, report.Checks)
+}
+
+func TestInspect_Errors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "binary")
+	require.NoError(t, os.WriteFile(path, []byte("not a binary"), 0o600))
+
+	_, err := Inspect(Options{Binary: path})
+	assert.ErrorContains(t, err, "failed to read the build info")
+
+	binary, err := os.Executable()
+	require.NoError(t, err)
+	_, err = Inspect(Options{Binary: binary, Env: []string{"CGO_ENABLED"}})
+	assert.ErrorContains(t, err, "must be KEY=VALUE")
+}
+
+func TestEnvMatches(t *testing.T) {
+	tests := []struct {
+		key, expected, actual string
+		want                  bool
+	}{
+		{"GOEXPERIMENT", "boringcrypto", "boringcrypto", true},
+		{"GOEXPERIMENT", "", "", true},
+		{"GOEXPERIMENT", "", "boringcrypto", false},
+		{"CGO_ENABLED", "1", "0", false},
+		{"GOFIPS140", "v1.0.0", "v1.0.0-c2097c7c", true},
+		{"GOFIPS140", "v1.0.0", "v1.0.0", true},
+		{"GOFIPS140", "v1.0.0", "v1.0.1-fbaa27f5", false},
+		{"GOFIPS140", "", "", true},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, envMatches(tt.key, tt.expected, tt.actual), "%s=%s recorded as %q", tt.key, tt.expected, tt.actual)
+	}
+}
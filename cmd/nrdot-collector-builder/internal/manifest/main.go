@@ -49,6 +49,15 @@ func runGoCommand(cfg *Config, args ...string) ([]byte, error) {
 	return stdout.Bytes(), nil
 }
 
+// GoVersion returns the version of the Go toolchain used to build the distribution.
+func (c *Config) GoVersion() (string, error) {
+	output, err := runGoCommand(c, "env", "GOVERSION")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
 func fetchAllModuleVersions(cfg *Config, modules []string) (map[string][]string, error) {
 	// Run `go list -m -versions <module1> <module2>` to fetch the versions for this module
 	output, err := runGoCommand(cfg, append([]string{"list", "-versions", "-m"}, modules...)...)
@@ -0,0 +1,235 @@
+// Copyright New Relic, Inc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package provenance
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"newrelic-collector-builder/internal/manifest"
+)
+
+const (
+	StatementType = "https://in-toto.io/Statement/v1"
+	PredicateType = "https://slsa.dev/provenance/v1"
+	// BuildType identifies the way distributions are built: OCB-generated
+	// sources compiled by goreleaser with the settings recorded in the
+	// internal parameters.
+	BuildType = "https://github.com/newrelic/nrdot-collector-releases/goreleaser@v1"
+
+	localBuilderID = "local"
+)
+
+// ErrNoSubjects is returned when none of the subject patterns match a file.
+var ErrNoSubjects = errors.New("no files match the subject patterns")
+
+// Statement is an in-toto statement carrying a SLSA v1 provenance predicate.
+// https://slsa.dev/spec/v1.0/provenance
+type Statement struct {
+	Type          string     `json:"_type"`
+	Subject       []Resource `json:"subject"`
+	PredicateType string     `json:"predicateType"`
+	Predicate     Predicate  `json:"predicate"`
+}
+
+type Predicate struct {
+	BuildDefinition BuildDefinition `json:"buildDefinition"`
+	RunDetails      RunDetails      `json:"runDetails"`
+}
+
+type BuildDefinition struct {
+	BuildType            string             `json:"buildType"`
+	ExternalParameters   ExternalParameters `json:"externalParameters"`
+	InternalParameters   InternalParameters `json:"internalParameters"`
+	ResolvedDependencies []Resource         `json:"resolvedDependencies"`
+}
+
+// ExternalParameters are the inputs chosen by whoever triggered the release.
+type ExternalParameters struct {
+	Distribution string `json:"distribution"`
+	Version      string `json:"version"`
+	Fips         bool   `json:"fips"`
+	FipsModule   string `json:"fipsModule,omitempty"`
+}
+
+// InternalParameters are the build settings derived from the release profile
+// and the toolchain the binaries were compiled with.
+type InternalParameters struct {
+	GoVersion string            `json:"goVersion"`
+	Env       map[string]string `json:"env"`
+	Flags     []string          `json:"flags,omitempty"`
+	Ldflags   []string          `json:"ldflags,omitempty"`
+	Tags      []string          `json:"tags,omitempty"`
+	Versions  manifest.Versions `json:"versions"`
+}
+
+// Resource is an in-toto resource descriptor.
+type Resource struct {
+	Name   string            `json:"name,omitempty"`
+	URI    string            `json:"uri,omitempty"`
+	Digest map[string]string `json:"digest"`
+}
+
+type RunDetails struct {
+	Builder  Builder   `json:"builder"`
+	Metadata *Metadata `json:"metadata,omitempty"`
+}
+
+type Builder struct {
+	ID string `json:"id"`
+}
+
+type Metadata struct {
+	InvocationID string `json:"invocationId,omitempty"`
+}
+
+// Options holds the build settings recorded in the provenance.
+type Options struct {
+	Distribution string
+	Version      string
+	Fips         bool
+	FipsModule   string // boringcrypto or native, empty unless Fips
+	Source       string // git URL of the repository
+	Commit       string
+	ManifestPath string
+	GoVersion    string
+	Env          []string // KEY=VALUE pairs
+	Flags        []string
+	Ldflags      []string
+	Tags         []string
+	Subjects     []string // glob patterns of the released files
+}
+
+// New builds the provenance of the files matching opts.Subjects.
+func New(opts Options, versions manifest.Versions) (Statement, error) {
+	subjects, err := resolveSubjects(opts.Subjects)
+	if err != nil {
+		return Statement{}, err
+	}
+
+	manifestDigest, err := sha256File(opts.ManifestPath)
+	if err != nil {
+		return Statement{}, fmt.Errorf("failed to digest manifest: %w", err)
+	}
+
+	env := make(map[string]string, len(opts.Env))
+	for _, e := range opts.Env {
+		key, value, ok := strings.Cut(e, "=")
+		if !ok {
+			return Statement{}, fmt.Errorf("invalid environment variable %q, must be KEY=VALUE", e)
+		}
+		env[key] = value
+	}
+
+	return Statement{
+		Type:          StatementType,
+		Subject:       subjects,
+		PredicateType: PredicateType,
+		Predicate: Predicate{
+			BuildDefinition: BuildDefinition{
+				BuildType: BuildType,
+				ExternalParameters: ExternalParameters{
+					Distribution: opts.Distribution,
+					Version:      opts.Version,
+					Fips:         opts.Fips,
+					FipsModule:   opts.FipsModule,
+				},
+				InternalParameters: InternalParameters{
+					GoVersion: opts.GoVersion,
+					Env:       env,
+					Flags:     opts.Flags,
+					Ldflags:   opts.Ldflags,
+					Tags:      opts.Tags,
+					Versions:  versions,
+				},
+				ResolvedDependencies: []Resource{
+					{
+						URI:    fmt.Sprintf("git+%s@%s", opts.Source, opts.Commit),
+						Digest: map[string]string{"gitCommit": opts.Commit},
+					},
+					{
+						Name:   filepath.Base(opts.ManifestPath),
+						Digest: map[string]string{"sha256": manifestDigest},
+					},
+				},
+			},
+			RunDetails: runDetails(),
+		},
+	}, nil
+}
+
+// runDetails identifies the GitHub Actions workflow run building the release,
+// falling back to a local builder outside of CI.
+func runDetails() RunDetails {
+	workflow := os.Getenv("GITHUB_WORKFLOW_REF")
+	if workflow == "" {
+		return RunDetails{Builder: Builder{ID: localBuilderID}}
+	}
+
+	server := os.Getenv("GITHUB_SERVER_URL")
+	return RunDetails{
+		Builder: Builder{ID: fmt.Sprintf("%s/%s", server, workflow)},
+		Metadata: &Metadata{
+			InvocationID: fmt.Sprintf("%s/%s/actions/runs/%s/attempts/%s",
+				server, os.Getenv("GITHUB_REPOSITORY"), os.Getenv("GITHUB_RUN_ID"), os.Getenv("GITHUB_RUN_ATTEMPT")),
+		},
+	}
+}
+
+// resolveSubjects digests every file matching patterns, named after its
+// path so binaries of different platforms don't collide.
+func resolveSubjects(patterns []string) ([]Resource, error) {
+	var files []string
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid subject pattern %q: %w", pattern, err)
+		}
+		files = append(files, matches...)
+	}
+	slices.Sort(files)
+	files = slices.Compact(files)
+
+	subjects := make([]Resource, 0, len(files))
+	for _, file := range files {
+		if info, err := os.Stat(file); err != nil || info.IsDir() {
+			continue
+		}
+		digest, err := sha256File(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to digest subject: %w", err)
+		}
+		subjects = append(subjects, Resource{
+			Name:   filepath.ToSlash(file),
+			Digest: map[string]string{"sha256": digest},
+		})
+	}
+
+	if len(subjects) == 0 {
+		return nil, fmt.Errorf("%w: %v", ErrNoSubjects, patterns)
+	}
+
+	return subjects, nil
+}
+
+func sha256File(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
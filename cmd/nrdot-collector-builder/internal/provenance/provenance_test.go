@@ -0,0 +1,96 @@
+// Copyright New Relic, Inc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package provenance
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"newrelic-collector-builder/internal/manifest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	t.Setenv("GITHUB_WORKFLOW_REF", "")
+	dir := t.TempDir()
+
+	manifestPath := filepath.Join(dir, "manifest.yaml")
+	require.NoError(t, os.WriteFile(manifestPath, []byte("dist:\n"), 0o600))
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "dist_linux_amd64_v1"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "dist_linux_amd64_v1", "dist"), []byte("binary"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "dist_1.0.0_linux_amd64.tar.gz"), []byte("archive"), 0o600))
+
+	versions := manifest.Versions{BetaCoreVersion: "v0.125.0", StableCoreVersion: "v1.31.0"}
+	statement, err := New(Options{
+		Distribution: "dist-fips",
+		Version:      "1.0.0",
+		Fips:         true,
+		FipsModule:   "boringcrypto",
+		Source:       "https://github.com/newrelic/nrdot-collector-releases.git",
+		Commit:       "abc123",
+		ManifestPath: manifestPath,
+		GoVersion:    "go1.24.11",
+		Env:          []string{"CGO_ENABLED=1", "GOEXPERIMENT=boringcrypto"},
+		Ldflags:      []string{"-w"},
+		Subjects: []string{
+			filepath.Join(dir, "dist_*", "dist*"),
+			filepath.Join(dir, "dist_*.tar.gz"),
+			filepath.Join(dir, "dist_*.tar.gz"),
+		},
+	}, versions)
+	require.NoError(t, err)
+
+	assert.Equal(t, StatementType, statement.Type)
+	assert.Equal(t, PredicateType, statement.PredicateType)
+	require.Len(t, statement.Subject, 2)
+	// sha256 of "archive"
+	assert.Equal(t, "0eb3e36bfb24dcd9bb1d1bece1531216b59539a8fde17ee80224af0653c92aa3", statement.Subject[0].Digest["sha256"])
+
+	external := statement.Predicate.BuildDefinition.ExternalParameters
+	assert.Equal(t, "boringcrypto", external.FipsModule)
+
+	params := statement.Predicate.BuildDefinition.InternalParameters
+	assert.Equal(t, map[string]string{"CGO_ENABLED": "1", "GOEXPERIMENT": "boringcrypto"}, params.Env)
+	assert.Equal(t, versions, params.Versions)
+	assert.Equal(t, "go1.24.11", params.GoVersion)
+
+	deps := statement.Predicate.BuildDefinition.ResolvedDependencies
+	require.Len(t, deps, 2)
+	assert.Equal(t, "git+https://github.com/newrelic/nrdot-collector-releases.git@abc123", deps[0].URI)
+	assert.Equal(t, "manifest.yaml", deps[1].Name)
+	assert.Len(t, deps[1].Digest["sha256"], 64)
+
+	assert.Equal(t, localBuilderID, statement.Predicate.RunDetails.Builder.ID)
+	assert.Nil(t, statement.Predicate.RunDetails.Metadata)
+}
+
+func TestNew_NoSubjects(t *testing.T) {
+	_, err := New(Options{Subjects: []string{filepath.Join(t.TempDir(), "*.deb")}}, manifest.Versions{})
+	assert.ErrorIs(t, err, ErrNoSubjects)
+}
+
+func TestNew_InvalidEnv(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "file")
+	require.NoError(t, os.WriteFile(file, nil, 0o600))
+
+	_, err := New(Options{ManifestPath: file, Subjects: []string{file}, Env: []string{"CGO_ENABLED"}}, manifest.Versions{})
+	assert.ErrorContains(t, err, "must be KEY=VALUE")
+}
+
+func TestRunDetails_GitHubActions(t *testing.T) {
+	t.Setenv("GITHUB_SERVER_URL", "https://github.com")
+	t.Setenv("GITHUB_WORKFLOW_REF", "newrelic/nrdot-collector-releases/.github/workflows/ci.yaml@refs/heads/main")
+	t.Setenv("GITHUB_REPOSITORY", "newrelic/nrdot-collector-releases")
+	t.Setenv("GITHUB_RUN_ID", "42")
+	t.Setenv("GITHUB_RUN_ATTEMPT", "1")
+
+	details := runDetails()
+	assert.Equal(t, "https://github.com/newrelic/nrdot-collector-releases/.github/workflows/ci.yaml@refs/heads/main", details.Builder.ID)
+	require.NotNil(t, details.Metadata)
+	assert.Equal(t, "https://github.com/newrelic/nrdot-collector-releases/actions/runs/42/attempts/1", details.Metadata.InvocationID)
+}
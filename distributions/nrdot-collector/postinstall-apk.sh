@@ -0,0 +1,24 @@
+#!/bin/sh
+# Copyright New Relic, Inc. All rights reserved.
+# SPDX-License-Identifier: Apache-2.0
+
+# ROOT runs the service as root. The OpenRC script reads command_user from
+# /etc/conf.d/nrdot-collector, which the package doesn't own, so the mode is
+# kept through upgrades until NRDOT_MODE is set again or the package is
+# purged. apk only passes NRDOT_MODE on with --preserve-env, otherwise the
+# file is left as it is.
+conf=/etc/conf.d/nrdot-collector
+if [ -n "${NRDOT_MODE}" ] && [ -f "$conf" ]; then
+    sed -i "/^command_user=/d" "$conf"
+fi
+if [ "${NRDOT_MODE}" = "ROOT" ]; then
+    mkdir -p "$(dirname "$conf")"
+    echo 'command_user="root:root"' >> "$conf"
+fi
+
+if command -v rc-update >/dev/null 2>&1; then
+    rc-update add nrdot-collector default
+    if [ -f /etc/nrdot-collector/config.yaml ]; then
+        rc-service nrdot-collector start
+    fi
+fi
@@ -0,0 +1,66 @@
+#!/bin/sh
+# Generated by cmd/goreleaser from postinstall.sh.tmpl, run `make generate-goreleaser` to update.
+
+# Copyright The OpenTelemetry Authors
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#       http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+# deb runs `postinst configure <version>` with the previously configured
+# version, empty on a first install, rpm passes the number of versions
+# installed once the transaction completes.
+action=install
+if { [ "$1" = "configure" ] && [ -n "$2" ]; } || [ "$1" -ge 2 ] 2>/dev/null; then
+    action=upgrade
+fi
+
+# Upgrades keep the mode the package was installed with, unless NRDOT_MODE
+# is set again.
+if [ -z "${NRDOT_MODE}" ] && [ -f /etc/nrdot-collector-fips/install-mode ]; then
+    . /etc/nrdot-collector-fips/install-mode
+fi
+case "${NRDOT_MODE}" in
+    ROOT|CAPABILITIES)
+        echo "NRDOT_MODE=${NRDOT_MODE}" > /etc/nrdot-collector-fips/install-mode
+        ;;
+    *)
+        rm -f /etc/nrdot-collector-fips/install-mode
+        ;;
+esac
+
+# Config migrations from older versions go here, keyed on the upgrade action.
+# None are needed yet.
+
+if command -v systemctl >/dev/null 2>&1; then
+    # ROOT runs the service as root, CAPABILITIES as the nrdot-collector user
+    # with a few capabilities and sandboxing. Both are drop-ins shipped in
+    # /usr/share/nrdot-collector-fips/systemd.
+    drop_in=/etc/systemd/system/nrdot-collector-fips.service.d/nrdot-mode.conf
+    case "${NRDOT_MODE}" in
+        ROOT|CAPABILITIES)
+            mkdir -p "$(dirname "$drop_in")"
+            ln -sf "/usr/share/nrdot-collector-fips/systemd/$(echo "${NRDOT_MODE}" | tr '[:upper:]' '[:lower:]').conf" "$drop_in"
+            ;;
+        *)
+            rm -f "$drop_in"
+            ;;
+    esac
+    systemctl daemon-reload
+    systemctl enable nrdot-collector-fips.service
+    if [ -f /etc/nrdot-collector-fips/config.yaml ]; then
+        if [ "$action" = "upgrade" ]; then
+            systemctl restart nrdot-collector-fips.service
+        else
+            systemctl start nrdot-collector-fips.service
+        fi
+    fi
+fi
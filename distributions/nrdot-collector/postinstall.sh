@@ -1,4 +1,5 @@
 #!/bin/sh
+# Generated by cmd/goreleaser from postinstall.sh.tmpl, run `make generate-goreleaser` to update.
 
 # Copyright The OpenTelemetry Authors
 #
@@ -14,13 +15,52 @@
 # See the License for the specific language governing permissions and
 # limitations under the License.
 
+# deb runs `postinst configure <version>` with the previously configured
+# version, empty on a first install, rpm passes the number of versions
+# installed once the transaction completes.
+action=install
+if { [ "$1" = "configure" ] && [ -n "$2" ]; } || [ "$1" -ge 2 ] 2>/dev/null; then
+    action=upgrade
+fi
+
+# Upgrades keep the mode the package was installed with, unless NRDOT_MODE
+# is set again.
+if [ -z "${NRDOT_MODE}" ] && [ -f /etc/nrdot-collector/install-mode ]; then
+    . /etc/nrdot-collector/install-mode
+fi
+case "${NRDOT_MODE}" in
+    ROOT|CAPABILITIES)
+        echo "NRDOT_MODE=${NRDOT_MODE}" > /etc/nrdot-collector/install-mode
+        ;;
+    *)
+        rm -f /etc/nrdot-collector/install-mode
+        ;;
+esac
+
+# Config migrations from older versions go here, keyed on the upgrade action.
+# None are needed yet.
+
 if command -v systemctl >/dev/null 2>&1; then
-    if [ "${NRDOT_MODE}" = "ROOT" ]; then
-        sed -i "/User=nrdot-collector/d" /lib/systemd/system/nrdot-collector.service
-        sed -i "/Group=nrdot-collector/d" /lib/systemd/system/nrdot-collector.service
-    fi
+    # ROOT runs the service as root, CAPABILITIES as the nrdot-collector user
+    # with a few capabilities and sandboxing. Both are drop-ins shipped in
+    # /usr/share/nrdot-collector/systemd.
+    drop_in=/etc/systemd/system/nrdot-collector.service.d/nrdot-mode.conf
+    case "${NRDOT_MODE}" in
+        ROOT|CAPABILITIES)
+            mkdir -p "$(dirname "$drop_in")"
+            ln -sf "/usr/share/nrdot-collector/systemd/$(echo "${NRDOT_MODE}" | tr '[:upper:]' '[:lower:]').conf" "$drop_in"
+            ;;
+        *)
+            rm -f "$drop_in"
+            ;;
+    esac
+    systemctl daemon-reload
     systemctl enable nrdot-collector.service
     if [ -f /etc/nrdot-collector/config.yaml ]; then
-        systemctl start nrdot-collector.service
+        if [ "$action" = "upgrade" ]; then
+            systemctl restart nrdot-collector.service
+        else
+            systemctl start nrdot-collector.service
+        fi
     fi
 fi
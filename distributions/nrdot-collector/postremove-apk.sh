@@ -0,0 +1,17 @@
+#!/bin/sh
+# Copyright New Relic, Inc. All rights reserved.
+# SPDX-License-Identifier: Apache-2.0
+
+# apk only runs this on removal, upgrades have scripts of their own. Like rpm,
+# apk has no purge, NRDOT_PURGE=true turns the removal into one. apk only
+# passes it on with --preserve-env.
+if [ "${NRDOT_PURGE}" = "true" ]; then
+    rm -rf /etc/nrdot-collector /var/lib/nrdot-collector /var/log/nrdot-collector
+    rm -f /etc/conf.d/nrdot-collector
+    if getent passwd nrdot-collector >/dev/null; then
+        deluser nrdot-collector
+    fi
+    if getent group nrdot-collector >/dev/null; then
+        delgroup nrdot-collector
+    fi
+fi
@@ -0,0 +1,40 @@
+#!/bin/sh
+# Generated by cmd/goreleaser from postremove.sh.tmpl, run `make generate-goreleaser` to update.
+# Copyright New Relic, Inc. All rights reserved.
+# SPDX-License-Identifier: Apache-2.0
+
+# deb passes remove, purge or upgrade, rpm the number of versions left
+# installed, 0 on removal. rpm has no purge, NRDOT_PURGE=true turns a removal
+# into one.
+action=remove
+if [ "$1" = "purge" ]; then
+    action=purge
+elif [ "$1" = "upgrade" ] || [ "$1" -ge 1 ] 2>/dev/null; then
+    action=upgrade
+fi
+if [ "$action" = "remove" ] && [ "${NRDOT_PURGE}" = "true" ]; then
+    action=purge
+fi
+
+if [ "$action" = "upgrade" ]; then
+    exit 0
+fi
+
+# The mode drop-in links to a file of the package. install-mode is kept until
+# a purge, a reinstall links it again.
+rm -f /etc/systemd/system/nrdot-collector-fips.service.d/nrdot-mode.conf
+rmdir /etc/systemd/system/nrdot-collector-fips.service.d 2>/dev/null || true
+if command -v systemctl >/dev/null 2>&1; then
+    systemctl daemon-reload
+fi
+
+if [ "$action" = "purge" ]; then
+    rm -rf /etc/nrdot-collector-fips /var/lib/nrdot-collector-fips
+    # The user is shared with the other variants of the distribution.
+    if [ ! -e /usr/bin/nrdot-collector ] && [ ! -e /usr/bin/nrdot-collector-fips-native ] && getent passwd nrdot-collector >/dev/null; then
+        userdel nrdot-collector
+        if getent group nrdot-collector >/dev/null; then
+            groupdel nrdot-collector
+        fi
+    fi
+fi
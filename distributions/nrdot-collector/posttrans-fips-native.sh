@@ -0,0 +1,14 @@
+#!/bin/sh
+# Generated by cmd/goreleaser from posttrans.sh.tmpl, run `make generate-goreleaser` to update.
+# Copyright New Relic, Inc. All rights reserved.
+# SPDX-License-Identifier: Apache-2.0
+
+# rpm runs the preremove script of the replaced package after postinstall, and
+# older versions stopped and disabled the service there on upgrades as well.
+# Make sure it is back once the transaction completes.
+if command -v systemctl >/dev/null 2>&1; then
+    systemctl enable nrdot-collector-fips-native.service
+    if [ -f /etc/nrdot-collector-fips-native/config.yaml ]; then
+        systemctl start nrdot-collector-fips-native.service
+    fi
+fi
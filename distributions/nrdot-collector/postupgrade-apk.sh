@@ -0,0 +1,21 @@
+#!/bin/sh
+# Copyright New Relic, Inc. All rights reserved.
+# SPDX-License-Identifier: Apache-2.0
+
+# apk runs this instead of postinstall on upgrades. The mode is kept in
+# /etc/conf.d/nrdot-collector, unless NRDOT_MODE is set again.
+conf=/etc/conf.d/nrdot-collector
+if [ -n "${NRDOT_MODE}" ] && [ -f "$conf" ]; then
+    sed -i "/^command_user=/d" "$conf"
+fi
+if [ "${NRDOT_MODE}" = "ROOT" ]; then
+    mkdir -p "$(dirname "$conf")"
+    echo 'command_user="root:root"' >> "$conf"
+fi
+
+# Config migrations from older versions go here. None are needed yet.
+
+# The service keeps running through upgrades, restart it on the new version.
+if command -v rc-service >/dev/null 2>&1; then
+    rc-service --ifstarted nrdot-collector restart
+fi
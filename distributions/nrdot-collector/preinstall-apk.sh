@@ -0,0 +1,17 @@
+#!/bin/sh
+# Copyright New Relic, Inc. All rights reserved.
+# SPDX-License-Identifier: Apache-2.0
+
+# Alpine ships busybox's adduser and addgroup instead of useradd.
+# apk runs scripts with a clean environment unless --preserve-env is passed,
+# so root mode can also be set beforehand in /etc/conf.d/nrdot-collector.
+conf=/etc/conf.d/nrdot-collector
+mode="${NRDOT_MODE}"
+if [ -z "$mode" ] && grep -qs '^command_user="root:root"' "$conf"; then
+  mode=ROOT
+fi
+# Create the user if the service doesn't run as root
+if [ "$mode" != "ROOT" ]; then
+  getent group nrdot-collector >/dev/null || addgroup -S nrdot-collector
+  getent passwd nrdot-collector >/dev/null || adduser -S -D -H -h /var/lib/nrdot-collector -s /sbin/nologin -G nrdot-collector nrdot-collector
+fi
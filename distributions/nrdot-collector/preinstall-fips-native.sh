@@ -0,0 +1,35 @@
+#!/bin/sh
+# Generated by cmd/goreleaser from preinstall.sh.tmpl, run `make generate-goreleaser` to update.
+
+# Copyright The OpenTelemetry Authors
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#       http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+# Installs from before install-mode was recorded ran as root by deleting the
+# User= line of the unit, which is still the old one at this point. Record
+# their mode so that the upgrade keeps it.
+unit=/lib/systemd/system/nrdot-collector-fips-native.service
+if [ -z "${NRDOT_MODE}" ] && [ ! -f /etc/nrdot-collector-fips-native/install-mode ] && [ -f "$unit" ] && ! grep -q '^User=' "$unit"; then
+    mkdir -p /etc/nrdot-collector-fips-native
+    echo "NRDOT_MODE=ROOT" > /etc/nrdot-collector-fips-native/install-mode
+fi
+
+# Upgrades keep the mode the package was installed with.
+if [ -z "${NRDOT_MODE}" ] && [ -f /etc/nrdot-collector-fips-native/install-mode ]; then
+    . /etc/nrdot-collector-fips-native/install-mode
+fi
+
+# Create the user if NRDOT_MODE is not set to root
+if [ "${NRDOT_MODE}" != "ROOT" ]; then
+  getent passwd nrdot-collector >/dev/null || useradd --system --user-group --no-create-home --shell /sbin/nologin nrdot-collector
+fi
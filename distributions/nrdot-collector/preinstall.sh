@@ -1,4 +1,5 @@
 #!/bin/sh
+# Generated by cmd/goreleaser from preinstall.sh.tmpl, run `make generate-goreleaser` to update.
 
 # Copyright The OpenTelemetry Authors
 #
@@ -14,6 +15,20 @@
 # See the License for the specific language governing permissions and
 # limitations under the License.
 
+# Installs from before install-mode was recorded ran as root by deleting the
+# User= line of the unit, which is still the old one at this point. Record
+# their mode so that the upgrade keeps it.
+unit=/lib/systemd/system/nrdot-collector.service
+if [ -z "${NRDOT_MODE}" ] && [ ! -f /etc/nrdot-collector/install-mode ] && [ -f "$unit" ] && ! grep -q '^User=' "$unit"; then
+    mkdir -p /etc/nrdot-collector
+    echo "NRDOT_MODE=ROOT" > /etc/nrdot-collector/install-mode
+fi
+
+# Upgrades keep the mode the package was installed with.
+if [ -z "${NRDOT_MODE}" ] && [ -f /etc/nrdot-collector/install-mode ]; then
+    . /etc/nrdot-collector/install-mode
+fi
+
 # Create the user if NRDOT_MODE is not set to root
 if [ "${NRDOT_MODE}" != "ROOT" ]; then
   getent passwd nrdot-collector >/dev/null || useradd --system --user-group --no-create-home --shell /sbin/nologin nrdot-collector
@@ -0,0 +1,8 @@
+#!/bin/sh
+# Copyright New Relic, Inc. All rights reserved.
+# SPDX-License-Identifier: Apache-2.0
+
+if command -v rc-service >/dev/null 2>&1; then
+    rc-service nrdot-collector stop
+    rc-update del nrdot-collector default
+fi
@@ -0,0 +1,28 @@
+#!/bin/sh
+# Generated by cmd/goreleaser from preremove.sh.tmpl, run `make generate-goreleaser` to update.
+
+# Copyright The OpenTelemetry Authors
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#       http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+# deb passes remove or upgrade, rpm the number of versions left installed, 0
+# on removal. The service keeps running through upgrades, postinstall restarts
+# it.
+if [ "$1" = "upgrade" ] || [ "$1" = "failed-upgrade" ] || [ "$1" -ge 1 ] 2>/dev/null; then
+    exit 0
+fi
+
+if command -v systemctl >/dev/null 2>&1; then
+    systemctl stop nrdot-collector-fips-native.service
+    systemctl disable nrdot-collector-fips-native.service
+fi
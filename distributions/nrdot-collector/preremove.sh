@@ -1,4 +1,5 @@
 #!/bin/sh
+# Generated by cmd/goreleaser from preremove.sh.tmpl, run `make generate-goreleaser` to update.
 
 # Copyright The OpenTelemetry Authors
 #
@@ -14,6 +15,13 @@
 # See the License for the specific language governing permissions and
 # limitations under the License.
 
+# deb passes remove or upgrade, rpm the number of versions left installed, 0
+# on removal. The service keeps running through upgrades, postinstall restarts
+# it.
+if [ "$1" = "upgrade" ] || [ "$1" = "failed-upgrade" ] || [ "$1" -ge 1 ] 2>/dev/null; then
+    exit 0
+fi
+
 if command -v systemctl >/dev/null 2>&1; then
     systemctl stop nrdot-collector.service
     systemctl disable nrdot-collector.service
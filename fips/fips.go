@@ -10,11 +10,14 @@ import (
 	"os"
 
 	"crypto/boring"
-_ "crypto/tls/fipsonly"
+	_ "crypto/tls/fipsonly"
+
+	"github.com/newrelic/nrdot-collector-releases/fips/fipsconverter"
 )
 
 func init() {
 	attestFIPS()
+	runSelfTests()
 }
 
 func attestFIPS() {
@@ -25,3 +28,10 @@ func attestFIPS() {
 		os.Exit(1)
 	}
 }
+
+// fipsStatus reports the FIPS status in the collector's build info and
+// telemetry. build-info is handled before attestFIPS runs, so the status comes
+// from boring.Enabled() rather than from the build.
+func fipsStatus() fipsInfo {
+	return fipsInfo(fipsconverter.CurrentStatus())
+}
@@ -0,0 +1,39 @@
+// Copyright New Relic, Inc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// GOFIPS140=v1.0.0 sets the fips140v1.0 tag, update it along with
+// FipsNativeVersion in cmd/goreleaser.
+
+//go:build fips140v1.0
+
+package main
+
+import (
+	"crypto/fips140"
+	"log"
+	"os"
+
+	"github.com/newrelic/nrdot-collector-releases/fips/fipsconverter"
+)
+
+func init() {
+	attestFIPS140()
+}
+
+// attestFIPS140 checks that the collector runs in FIPS 140-3 mode, which the
+// frozen Go Cryptographic Module enables by default. In that mode crypto/tls
+// only negotiates FIPS-approved settings.
+func attestFIPS140() {
+	if !fips140.Enabled() {
+		log.Print("ERROR: not running in FIPS 140-3 mode, GODEBUG must not set fips140=off")
+		os.Exit(1)
+	}
+	log.Printf("Using the Go Cryptographic Module %s and running in FIPS 140-3 mode", fips140.Version())
+}
+
+// fipsStatus reports the FIPS 140-3 status in the collector's build info and
+// telemetry. build-info is handled before attestFIPS140 runs, so the status
+// comes from fips140.Enabled() rather than from the build.
+func fipsStatus() fipsInfo {
+	return fipsInfo(fipsconverter.CurrentStatus())
+}
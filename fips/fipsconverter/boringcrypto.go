@@ -0,0 +1,23 @@
+// Copyright New Relic, Inc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build goexperiment.boringcrypto
+
+package fipsconverter
+
+import "crypto/boring"
+
+// fipsCipherSuites are the suites crypto/tls/fipsonly allows with
+// BoringCrypto, TLS 1.2 ones first.
+var fipsCipherSuites = []string{
+	"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256",
+	"TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384",
+	"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256",
+	"TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384",
+	"TLS_AES_128_GCM_SHA256",
+	"TLS_AES_256_GCM_SHA384",
+}
+
+func currentStatus() Status {
+	return boringCryptoStatus(boring.Enabled())
+}
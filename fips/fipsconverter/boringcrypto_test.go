@@ -0,0 +1,14 @@
+// Copyright New Relic, Inc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build goexperiment.boringcrypto
+
+package fipsconverter
+
+import "testing"
+
+func TestCurrentStatus(t *testing.T) {
+	if got, want := CurrentStatus(), boringCryptoStatus(true); got != want {
+		t.Errorf("CurrentStatus() = %+v, want %+v", got, want)
+	}
+}
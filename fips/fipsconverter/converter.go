@@ -0,0 +1,88 @@
+// Copyright New Relic, Inc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package fipsconverter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"go.opentelemetry.io/collector/confmap"
+)
+
+// healthCheckType is the type of the health_check extension, whose instances
+// are named health_check or health_check/<name>.
+const healthCheckType = "health_check"
+
+// healthCheckStatuses are the default bodies of the health_check extension.
+var healthCheckStatuses = map[string]string{
+	"healthy":   "Server available",
+	"unhealthy": "Server not available",
+}
+
+// NewFactory returns the factory of the converter, listed in the converters
+// of the manifests of FIPS builds.
+func NewFactory() confmap.ConverterFactory {
+	return confmap.NewConverterFactory(func(confmap.ConverterSettings) confmap.Converter {
+		return converter{status: CurrentStatus(), cipherSuites: fipsCipherSuites}
+	})
+}
+
+type converter struct {
+	status Status
+	// cipherSuites are the suites the FIPS module allows.
+	cipherSuites []string
+}
+
+// Convert fails if a receiver or exporter has TLS settings that can't be
+// satisfied in FIPS mode, so that the collector doesn't start instead of
+// failing its first connection. The configuration is checked once every
+// source has been merged and its ${...} references expanded, whatever the
+// providers. It then makes the health_check extensions of the configuration
+// answer with the FIPS status, so that probes and fleet dashboards can check
+// it alongside the health of the collector.
+func (c converter) Convert(_ context.Context, conf *confmap.Conf) error {
+	if violations := tlsViolations(conf.ToStringMap(), c.cipherSuites); len(violations) > 0 {
+		errs := make([]error, 0, len(violations))
+		for _, violation := range violations {
+			errs = append(errs, errors.New(violation))
+		}
+		return errors.Join(errs...)
+	}
+
+	bodies := healthCheckBodies(conf.ToStringMap(), c.status)
+	if len(bodies) == 0 {
+		return nil
+	}
+	return conf.Merge(confmap.NewFromStringMap(map[string]any{"extensions": bodies}))
+}
+
+// healthCheckBodies returns the response_body of each health_check extension
+// of conf that doesn't set one, holding the usual status and the FIPS status.
+// Extensions that aren't declared aren't added.
+func healthCheckBodies(conf map[string]any, status Status) map[string]any {
+	extensions, _ := conf["extensions"].(map[string]any)
+
+	bodies := make(map[string]any)
+	for id, config := range extensions {
+		if componentType, _, _ := strings.Cut(id, "/"); componentType != healthCheckType {
+			continue
+		}
+		if settings, _ := config.(map[string]any); settings["response_body"] != nil {
+			continue
+		}
+
+		body := make(map[string]any, len(healthCheckStatuses))
+		for name, health := range healthCheckStatuses {
+			b, _ := json.Marshal(struct {
+				Status string `json:"status"`
+				Fips   Status `json:"fips"`
+			}{health, status})
+			body[name] = string(b)
+		}
+		bodies[id] = map[string]any{"response_body": body}
+	}
+	return bodies
+}
@@ -0,0 +1,151 @@
+// Copyright New Relic, Inc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package fipsconverter
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/collector/confmap"
+)
+
+func TestHealthCheckBodies(t *testing.T) {
+	status := Status{Mode: "on", Backend: "boringcrypto", ModuleVersion: boringSSLCommit}
+	custom := map[string]any{"healthy": "ok", "unhealthy": "ko"}
+
+	tests := []struct {
+		name string
+		conf map[string]any
+		want []string
+	}{
+		{
+			name: "default instance",
+			conf: map[string]any{"extensions": map[string]any{"health_check": map[string]any{"endpoint": "0.0.0.0:13133"}}},
+			want: []string{"health_check"},
+		},
+		{
+			name: "instance without settings",
+			conf: map[string]any{"extensions": map[string]any{"health_check": nil}},
+			want: []string{"health_check"},
+		},
+		{
+			name: "named instances",
+			conf: map[string]any{"extensions": map[string]any{"health_check/a": nil, "health_check/b": nil, "pprof": nil}},
+			want: []string{"health_check/a", "health_check/b"},
+		},
+		{
+			name: "response_body of the configuration",
+			conf: map[string]any{"extensions": map[string]any{"health_check": map[string]any{"response_body": custom}}},
+		},
+		{
+			name: "no health_check extension",
+			conf: map[string]any{"extensions": map[string]any{"health_checker": nil}},
+		},
+		{
+			name: "no extensions",
+			conf: map[string]any{"receivers": map[string]any{"otlp": nil}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bodies := healthCheckBodies(tt.conf, status)
+			if len(bodies) != len(tt.want) {
+				t.Fatalf("healthCheckBodies() = %v, want bodies for %v", bodies, tt.want)
+			}
+			for _, id := range tt.want {
+				body, _ := bodies[id].(map[string]any)["response_body"].(map[string]any)
+				healthy, _ := body["healthy"].(string)
+				var got struct {
+					Status string `json:"status"`
+					Fips   Status `json:"fips"`
+				}
+				if err := json.Unmarshal([]byte(healthy), &got); err != nil {
+					t.Fatalf("healthy body of %s = %q: %v", id, healthy, err)
+				}
+				if got.Status != "Server available" || got.Fips != status {
+					t.Errorf("healthy body of %s = %+v, want the FIPS status %+v", id, got, status)
+				}
+				if _, ok := body["unhealthy"].(string); !ok {
+					t.Errorf("body of %s = %v, missing unhealthy", id, body)
+				}
+			}
+		})
+	}
+}
+
+func TestConvert(t *testing.T) {
+	conf := confmap.NewFromStringMap(map[string]any{
+		"extensions": map[string]any{
+			"health_check":        map[string]any{"endpoint": "0.0.0.0:13133"},
+			"health_check/custom": map[string]any{"response_body": map[string]any{"healthy": "ok"}},
+		},
+	})
+	status := Status{Mode: "only", Backend: "native", ModuleVersion: "v1.0.0"}
+
+	if err := (converter{status: status}).Convert(context.Background(), conf); err != nil {
+		t.Fatalf("Convert() = %v", err)
+	}
+	if got := conf.Get("extensions::health_check::endpoint"); got != "0.0.0.0:13133" {
+		t.Errorf("endpoint = %v, want it kept", got)
+	}
+	if got := conf.Get("extensions::health_check::response_body::healthy"); got == nil {
+		t.Error("Convert() didn't set the response_body of health_check")
+	}
+	if got, want := conf.Get("extensions::health_check/custom::response_body"), map[string]any{"healthy": "ok"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("response_body of health_check/custom = %v, want %v", got, want)
+	}
+}
+
+// mapProvider serves the configuration sources of the tests.
+type mapProvider map[string]any
+
+func (p mapProvider) Retrieve(_ context.Context, uri string, _ confmap.WatcherFunc) (*confmap.Retrieved, error) {
+	return confmap.NewRetrieved(p[strings.TrimPrefix(uri, "test:")])
+}
+
+func (mapProvider) Scheme() string { return "test" }
+
+func (mapProvider) Shutdown(context.Context) error { return nil }
+
+func TestConvert_ResolvedConfig(t *testing.T) {
+	sources := mapProvider{
+		"config": map[string]any{
+			"exporters": map[string]any{"otlp": map[string]any{"tls": map[string]any{"min_version": "${test:version}"}}},
+		},
+		"version": "1.1",
+		// a later source, as --set flags are
+		"override": map[string]any{
+			"exporters": map[string]any{"otlp/backup": map[string]any{"tls": map[string]any{"insecure_skip_verify": true}}},
+		},
+	}
+
+	providers := confmap.NewProviderFactory(func(confmap.ProviderSettings) confmap.Provider {
+		return sources
+	})
+	converters := confmap.NewConverterFactory(func(confmap.ConverterSettings) confmap.Converter {
+		return converter{cipherSuites: []string{"TLS_AES_128_GCM_SHA256"}}
+	})
+	resolver, err := confmap.NewResolver(confmap.ResolverSettings{
+		URIs:               []string{"test:config", "test:override"},
+		ProviderFactories:  []confmap.ProviderFactory{providers},
+		ConverterFactories: []confmap.ConverterFactory{converters},
+	})
+	if err != nil {
+		t.Fatalf("NewResolver() = %v", err)
+	}
+
+	_, err = resolver.Resolve(context.Background())
+	for _, want := range []string{
+		`exporters::otlp: tls::min_version "1.1" isn't allowed in FIPS mode`,
+		"exporters::otlp/backup: tls::insecure_skip_verify can't be true in FIPS mode",
+	} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Resolve() = %v, want error containing %q", err, want)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+// Copyright New Relic, Inc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// GOFIPS140=v1.0.0 sets the fips140v1.0 tag, update it along with
+// FipsNativeVersion in cmd/goreleaser.
+
+//go:build fips140v1.0
+
+package fipsconverter
+
+import "crypto/fips140"
+
+// fipsCipherSuites are the suites crypto/tls allows in FIPS 140-3 mode, TLS
+// 1.2 ones first.
+var fipsCipherSuites = []string{
+	"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256",
+	"TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384",
+	"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256",
+	"TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384",
+	"TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA256",
+	"TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA256",
+	"TLS_AES_128_GCM_SHA256",
+	"TLS_AES_256_GCM_SHA384",
+}
+
+func currentStatus() Status {
+	return nativeStatus(fips140.Enabled(), fips140.Enforced(), fips140.Version())
+}
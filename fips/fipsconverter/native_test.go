@@ -0,0 +1,14 @@
+// Copyright New Relic, Inc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build fips140v1.0
+
+package fipsconverter
+
+import "testing"
+
+func TestCurrentStatus(t *testing.T) {
+	if got := CurrentStatus(); got.Mode == "off" || got.Backend != "native" || got.ModuleVersion == "" {
+		t.Errorf("CurrentStatus() = %+v, want the native module enabled", got)
+	}
+}
@@ -0,0 +1,14 @@
+// Copyright New Relic, Inc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !goexperiment.boringcrypto && !fips140v1.0
+
+package fipsconverter
+
+// fipsCipherSuites is empty without a FIPS module, the converter is only part
+// of FIPS builds.
+var fipsCipherSuites []string
+
+func currentStatus() Status {
+	return Status{Mode: "off"}
+}
@@ -0,0 +1,50 @@
+// Copyright New Relic, Inc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package fipsconverter is the confmap converter of FIPS collectors. It
+// reports the FIPS status of the running collector and runs on the resolved
+// configuration, after every source has been merged and expanded.
+package fipsconverter
+
+// boringSSLCommit is the BoringSSL commit of the BoringCrypto module embedded
+// in the Go toolchain, it must match fips/spec.yaml.
+const boringSSLCommit = "0c6f40132b828e92ba365c6b7680e32820c63fa7"
+
+// Status is the FIPS 140 status of the running collector: its mode (off, on
+// or only, as with GODEBUG=fips140), the crypto backend and the version of
+// the validated module. It matches the fipsInfo of the collector's build
+// info.
+type Status struct {
+	Mode          string `json:"mode"`
+	Backend       string `json:"backend,omitempty"`
+	ModuleVersion string `json:"moduleVersion,omitempty"`
+}
+
+// CurrentStatus reports the FIPS status of the running collector, as the
+// crypto module it is built with sees it rather than as it was built.
+func CurrentStatus() Status {
+	return currentStatus()
+}
+
+// boringCryptoStatus is the status of a BoringCrypto build.
+// crypto/tls/fipsonly restricts TLS to FIPS-approved settings, other uses of
+// non-approved algorithms aren't rejected, hence on rather than only.
+func boringCryptoStatus(enabled bool) Status {
+	if !enabled {
+		return Status{Mode: "off"}
+	}
+	return Status{Mode: "on", Backend: "boringcrypto", ModuleVersion: boringSSLCommit}
+}
+
+// nativeStatus is the status of a build with Go's native FIPS 140-3 module
+// at version.
+func nativeStatus(enabled, enforced bool, version string) Status {
+	switch {
+	case !enabled:
+		return Status{Mode: "off"}
+	case enforced:
+		return Status{Mode: "only", Backend: "native", ModuleVersion: version}
+	default:
+		return Status{Mode: "on", Backend: "native", ModuleVersion: version}
+	}
+}
@@ -0,0 +1,42 @@
+// Copyright New Relic, Inc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package fipsconverter
+
+import "testing"
+
+func TestBoringCryptoStatus(t *testing.T) {
+	tests := []struct {
+		enabled bool
+		want    Status
+	}{
+		{enabled: true, want: Status{Mode: "on", Backend: "boringcrypto", ModuleVersion: boringSSLCommit}},
+		{enabled: false, want: Status{Mode: "off"}},
+	}
+
+	for _, tt := range tests {
+		if got := boringCryptoStatus(tt.enabled); got != tt.want {
+			t.Errorf("boringCryptoStatus(%v) = %+v, want %+v", tt.enabled, got, tt.want)
+		}
+	}
+}
+
+func TestNativeStatus(t *testing.T) {
+	tests := []struct {
+		name              string
+		enabled, enforced bool
+		want              Status
+	}{
+		{name: "enabled", enabled: true, want: Status{Mode: "on", Backend: "native", ModuleVersion: "v1.0.0"}},
+		{name: "enforced", enabled: true, enforced: true, want: Status{Mode: "only", Backend: "native", ModuleVersion: "v1.0.0"}},
+		{name: "disabled", want: Status{Mode: "off"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := nativeStatus(tt.enabled, tt.enforced, "v1.0.0"); got != tt.want {
+				t.Errorf("nativeStatus() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
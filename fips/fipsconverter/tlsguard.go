@@ -0,0 +1,107 @@
+// Copyright New Relic, Inc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package fipsconverter
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var (
+	// fipsTLSVersions are the TLS versions both FIPS modules allow.
+	fipsTLSVersions = []string{"1.2", "1.3"}
+	// guardedSections hold the components whose TLS settings are checked.
+	guardedSections = []string{"receivers", "exporters"}
+)
+
+// tlsViolations lists the TLS settings of the guarded components of the
+// resolved configuration that FIPS mode doesn't allow, naming the component
+// and the setting. cipherSuites are the suites the FIPS module allows.
+func tlsViolations(conf map[string]any, cipherSuites []string) []string {
+	var violations []string
+	for _, section := range guardedSections {
+		components, _ := conf[section].(map[string]any)
+		for _, id := range slices.Sorted(maps.Keys(components)) {
+			for _, block := range tlsBlocks(components[id], "") {
+				component := fmt.Sprintf("%s::%s", section, id)
+				for _, violation := range block.violations(cipherSuites) {
+					violations = append(violations, fmt.Sprintf("%s: %s%s", component, block.path, violation))
+				}
+			}
+		}
+	}
+	return violations
+}
+
+// tlsBlock is a tls setting of a component, at path inside its config.
+type tlsBlock struct {
+	path     string
+	settings map[string]any
+}
+
+// tlsBlocks finds the tls settings in the config of a component, e.g. those
+// of each protocol of the otlp receiver.
+func tlsBlocks(config any, path string) []tlsBlock {
+	settings, ok := config.(map[string]any)
+	if !ok {
+		return nil
+	}
+
+	var blocks []tlsBlock
+	for _, key := range slices.Sorted(maps.Keys(settings)) {
+		if tls, ok := settings[key].(map[string]any); ok && key == "tls" {
+			blocks = append(blocks, tlsBlock{path: path + "tls::", settings: tls})
+			continue
+		}
+		blocks = append(blocks, tlsBlocks(settings[key], path+key+"::")...)
+	}
+	return blocks
+}
+
+func (b tlsBlock) violations(cipherSuites []string) []string {
+	var violations []string
+
+	for _, key := range []string{"min_version", "max_version"} {
+		if version, ok := settingString(b.settings[key]); ok && version != "" && !slices.Contains(fipsTLSVersions, version) {
+			violations = append(violations, fmt.Sprintf("%s %q isn't allowed in FIPS mode, use one of %s", key, version, strings.Join(fipsTLSVersions, ", ")))
+		}
+	}
+	if skip, ok := settingString(b.settings["insecure_skip_verify"]); ok && skip == "true" {
+		violations = append(violations, "insecure_skip_verify can't be true in FIPS mode, certificates must be verified")
+	}
+
+	suites, _ := b.settings["cipher_suites"].([]any)
+	for _, suite := range suites {
+		if name, ok := settingString(suite); ok && !slices.Contains(cipherSuites, name) {
+			violations = append(violations, fmt.Sprintf("cipher_suites entry %s isn't allowed in FIPS mode, use one of %s", name, strings.Join(cipherSuites, ", ")))
+		}
+	}
+
+	return violations
+}
+
+// settingString returns the value of a scalar setting of the resolved
+// configuration as a string, or false if it's unset.
+func settingString(value any) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case bool:
+		return strconv.FormatBool(v), true
+	case float64:
+		// unquoted versions such as 1.0 are decoded as numbers
+		s := strconv.FormatFloat(v, 'f', -1, 64)
+		if !strings.Contains(s, ".") {
+			s += ".0"
+		}
+		return s, true
+	case int:
+		return strconv.Itoa(v), true
+	default:
+		return "", false
+	}
+}
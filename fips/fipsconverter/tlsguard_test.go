@@ -0,0 +1,99 @@
+// Copyright New Relic, Inc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package fipsconverter
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestTLSViolations(t *testing.T) {
+	suites := []string{"TLS_AES_128_GCM_SHA256", "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"}
+
+	tests := []struct {
+		name string
+		conf map[string]any
+		want []string
+	}{
+		{
+			name: "allowed settings",
+			conf: map[string]any{"exporters": map[string]any{"otlp": map[string]any{"tls": map[string]any{
+				"min_version":   "1.2",
+				"max_version":   1.3,
+				"cipher_suites": []any{"TLS_AES_128_GCM_SHA256"},
+			}}}},
+		},
+		{
+			name: "unset versions",
+			conf: map[string]any{"exporters": map[string]any{"otlp": map[string]any{"tls": map[string]any{"min_version": ""}}}},
+		},
+		{
+			name: "old min_version",
+			conf: map[string]any{"exporters": map[string]any{"otlp": map[string]any{"tls": map[string]any{"min_version": "1.0"}}}},
+			want: []string{`exporters::otlp: tls::min_version "1.0" isn't allowed in FIPS mode, use one of 1.2, 1.3`},
+		},
+		{
+			name: "unquoted max_version",
+			conf: map[string]any{"exporters": map[string]any{"otlp": map[string]any{"tls": map[string]any{"max_version": 1.1}}}},
+			want: []string{`exporters::otlp: tls::max_version "1.1" isn't allowed in FIPS mode, use one of 1.2, 1.3`},
+		},
+		{
+			name: "integer version",
+			conf: map[string]any{"exporters": map[string]any{"otlp": map[string]any{"tls": map[string]any{"min_version": 1.0}}}},
+			want: []string{`exporters::otlp: tls::min_version "1.0" isn't allowed in FIPS mode, use one of 1.2, 1.3`},
+		},
+		{
+			name: "insecure_skip_verify",
+			conf: map[string]any{"exporters": map[string]any{"otlphttp": map[string]any{"tls": map[string]any{"insecure_skip_verify": true}}}},
+			want: []string{"exporters::otlphttp: tls::insecure_skip_verify can't be true in FIPS mode, certificates must be verified"},
+		},
+		{
+			name: "insecure_skip_verify as a string",
+			conf: map[string]any{"exporters": map[string]any{"otlphttp": map[string]any{"tls": map[string]any{"insecure_skip_verify": "true"}}}},
+			want: []string{"exporters::otlphttp: tls::insecure_skip_verify can't be true in FIPS mode, certificates must be verified"},
+		},
+		{
+			name: "disallowed cipher suite",
+			conf: map[string]any{"exporters": map[string]any{"otlp": map[string]any{"tls": map[string]any{
+				"cipher_suites": []any{"TLS_AES_128_GCM_SHA256", "TLS_RSA_WITH_RC4_128_SHA"},
+			}}}},
+			want: []string{"exporters::otlp: tls::cipher_suites entry TLS_RSA_WITH_RC4_128_SHA isn't allowed in FIPS mode, use one of TLS_AES_128_GCM_SHA256, TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"},
+		},
+		{
+			name: "nested receiver protocols",
+			conf: map[string]any{"receivers": map[string]any{"otlp": map[string]any{"protocols": map[string]any{
+				"grpc": map[string]any{"tls": map[string]any{"min_version": "1.1"}},
+				"http": map[string]any{"tls": map[string]any{"insecure_skip_verify": false}},
+			}}}},
+			want: []string{`receivers::otlp: protocols::grpc::tls::min_version "1.1" isn't allowed in FIPS mode, use one of 1.2, 1.3`},
+		},
+		{
+			name: "sorted components",
+			conf: map[string]any{"exporters": map[string]any{
+				"otlp/b": map[string]any{"tls": map[string]any{"insecure_skip_verify": true}},
+				"otlp/a": map[string]any{"tls": map[string]any{"insecure_skip_verify": true}},
+			}},
+			want: []string{
+				"exporters::otlp/a: tls::insecure_skip_verify can't be true in FIPS mode, certificates must be verified",
+				"exporters::otlp/b: tls::insecure_skip_verify can't be true in FIPS mode, certificates must be verified",
+			},
+		},
+		{
+			name: "unguarded sections",
+			conf: map[string]any{"extensions": map[string]any{"oauth2client": map[string]any{"tls": map[string]any{"insecure_skip_verify": true}}}},
+		},
+		{
+			name: "components without settings",
+			conf: map[string]any{"receivers": map[string]any{"otlp": nil}, "exporters": map[string]any{"debug": nil}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tlsViolations(tt.conf, suites); !slices.Equal(got, tt.want) {
+				t.Errorf("tlsViolations() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
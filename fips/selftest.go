@@ -0,0 +1,217 @@
+// Copyright New Relic, Inc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build goexperiment.boringcrypto
+
+package main
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+)
+
+// selfTest is a known-answer test of a primitive the collector's TLS stack
+// uses through BoringCrypto.
+type selfTest struct {
+	algorithm string
+	run       func() error
+}
+
+// selfTests cover the hashes, HMAC, AEAD and signatures of the cipher suites
+// crypto/tls/fipsonly allows. The hash, HMAC and AES-GCM vectors are the
+// published FIPS 180-4, RFC 4231 (test case 2) and GCM specification (test
+// case 4) ones, the signature keys only serve these tests.
+var selfTests = []selfTest{
+	{"SHA-256", func() error {
+		sum := sha256.Sum256([]byte("abc"))
+		return checkAnswer(sum[:], "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad")
+	}},
+	{"SHA-384", func() error {
+		sum := sha512.Sum384([]byte("abc"))
+		return checkAnswer(sum[:], "cb00753f45a35e8bb5a03d699ac65007272c32ab0eded1631a8b605a43ff5bed8086072ba1e7cc2358baeca134c825a7")
+	}},
+	{"HMAC-SHA-256", func() error {
+		mac := hmac.New(sha256.New, []byte("Jefe"))
+		mac.Write([]byte("what do ya want for nothing?"))
+		return checkAnswer(mac.Sum(nil), "5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843")
+	}},
+	{"AES-128-GCM", selfTestAESGCM},
+	{"ECDSA-P256-SHA-256", selfTestECDSA},
+	{"RSA-2048-PKCS1v15-SHA-256", selfTestRSA},
+}
+
+// selfTestMessage is the message signed by the signature tests.
+var selfTestMessage = []byte("NRDOT FIPS self-test")
+
+// ecdsaSelfTestKey and rsaSelfTestKey are base64 PKCS #8 keys generated for
+// the signature tests.
+const (
+	ecdsaSelfTestKey = `
+MIGHAgEAMBMGByqGSM49AgEGCCqGSM49AwEHBG0wawIBAQQgn0Q4uRp0Iia2N993
+7bTUwBQdUZBsbTg50DsVjutV4eyhRANCAAReIDfqLxg3jgHa2fqRuapwSsMDXppA
++tdrhuksMGuJUpeCrkcWc0UeDimz/V5L1pb4sIfyoFlDOlK6I9h1qWOu`
+	rsaSelfTestKey = `
+MIIEvQIBADANBgkqhkiG9w0BAQEFAASCBKcwggSjAgEAAoIBAQDb2MtiwDoaoER4
+Ocm5yOMWQnOXTfSMlw4/x92j9TyL0bcp/iKtkQlSf0FZLVAnWYp8fQ1QDVy9O/+2
+6QxQX457bonofgalKfYdJR8cJo1muDfUoAMTjnrk1wQChDOSgJ1wmN4C/1hNSSdh
+PWuyKJBWk3X8i2EPXBaRAiIbaGMWApA4pUFO+Tfrdo7F1U3FB1xJ0q2PcNUiTskp
+Q1VdYRswfq7531HMUFCB1hjGN8z/S3NKTuhNdoNXKV0EgjdZlefcCFfqTyO4KIVi
+PWWr6pssvP3lw+Oe8wfqsPrJoyJPjmnj3iFGJ3M8Gi02AKMhc8ME7e5bJdKTq6y4
+NOg0lgBZAgMBAAECggEADKuM5S8Q6jHsYEVsU9G5DLJduQ2cF3IpNxHMu0NJ7wDy
+HnBwDTK7p85/tKUA5pOpgI0wdDmg66MGlMMPm542NvTi68w/k8s8TOezyEqMgawC
+Q7XJF5pxiLi/mWmHE11oNXIJ8YBH1YRuyZLhnw3pa6Pp+9kIifsTrd3Cm3PDqiP2
+Rsv01iEUMEZn+6EMR00z2y9AjTGT/7xeEVTdG/1TbEUox00bnkcKYaOrSz4ai3H3
+FjWmeMG5oWwLIGMclX4woFFH1KB/qQ3rwhz+mOoqS3gIM5aCkSsChScckqe58cwM
+ezWu5/eICvvNF6FdpvKNwQsxm03trx6jVEtnIxJTNQKBgQDeUmG+/p6CzHCVYy1T
+IvV2KBLmZlu8Xi1Vm7utTYTTMCRHffR1OEu0/5Xc7BJULeOuhrGZq8GDy3+UKLsY
+RHBsyzBvwfgzwgBSa4S57nAT1173g2yuhtqQu6jYb5c8aov9bHtLdQzmAYmboGSH
+ehb+kl3XeBj98KRW09QMdba97wKBgQD9Jm8u8Llwxrw2p6caqy/7Y1n+B+4LDbal
+QUiXq2IMYi+h8iGQgSGBGMitQHcXhLaPI6y26lo3YpyH1F+xcYtGtM5oZqx0XRLL
+MTsqFfIkzt9NGmEXszOqeywTUhiZilJzkMq7aCcXDj+AEXpnI1DKcHcaT1L7eIJ1
+e0WVBTHuNwKBgQCCHK6uC/AS1rkxBKkbFcOYUh21j8Qyet+HUaxF4a9RtcQVvyZZ
+kAfmtworEFItx+mPU68PI5EOpANXX3sX2b3+PfBsB5ZOHET594jnFB+tzQb8ToNF
+pRC3UcygVrapGiYxu9Jwt+FDnzS8yuLq0s4wUNLw7o2Z2MMlObXJUP8RKwKBgBHC
+vo42iWwmJVJYVNsEI6upfsjZ2ZOg9K2HdQZfGdPwl5LvH/e5d5ZNHNc67KATyZMl
+V5rFumqkWoMVHVmfs7vUN58PhQ3s8cKbw6DiRUjb0sLgFOvKwNc3KRJ58gvjroE5
+KWAYoJV3hHEByOqviZ2KYnDQG+xUuiJv8aPQ533lAoGAYvbI5blGSJUaZORLzzcN
+Q1R7j66Di1k8NEaggMXs0vh2Co/YIbLwmffM7TlX91iBfVpVrXOV9rCBg/ow8V3f
+nGuTNLU5ZnnP7tV/eiDHOTCtXcZHKe/8YRSqCsAfGcKbzvsYQBOtK7LzJKqibyZo
+Vkk6pvFI1xXqP6vsnQfRDCs=`
+)
+
+// runSelfTests runs the known-answer tests and exits if one fails, before the
+// collector starts any receiver. Each result is logged as a structured record.
+func runSelfTests() {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+
+	start := time.Now()
+	failed := 0
+	for _, test := range selfTests {
+		if err := test.run(); err != nil {
+			failed++
+			logger.Error("FIPS self-test failed", "algorithm", test.algorithm, "error", err)
+			continue
+		}
+		logger.Info("FIPS self-test passed", "algorithm", test.algorithm)
+	}
+
+	if failed > 0 {
+		logger.Error("FIPS self-tests failed", "failed", failed, "total", len(selfTests))
+		os.Exit(1)
+	}
+	logger.Info("FIPS self-tests passed", "total", len(selfTests), "duration", time.Since(start))
+}
+
+// checkAnswer compares a result with its known answer in hex.
+func checkAnswer(result []byte, answer string) error {
+	expected, err := hex.DecodeString(answer)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(result, expected) {
+		return fmt.Errorf("got %x, want %s", result, answer)
+	}
+	return nil
+}
+
+func selfTestAESGCM() error {
+	key, _ := hex.DecodeString("feffe9928665731c6d6a8f9467308308")
+	nonce, _ := hex.DecodeString("cafebabefacedbaddecaf888")
+	plaintext, _ := hex.DecodeString("d9313225f88406e5a55909c5aff5269a86a7a9531534f7da2e4c303d8a318a721c3c0c95956809532fcf0e2449a6b525b16aedf5aa0de657ba637b39")
+	additionalData, _ := hex.DecodeString("feedfacedeadbeeffeedfacedeadbeefabaddad2")
+	const sealed = "42831ec2217774244b7221b784d0d49ce3aa212f2c02a4e035c17e2329aca12e21d514b25466931c7d8f6a5aac84aa051ba30b396a0aac973d58e091" +
+		"5bc94fbc3221a5db94fae95ae7121a47"
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return err
+	}
+	ciphertext := aead.Seal(nil, nonce, plaintext, additionalData)
+	if err := checkAnswer(ciphertext, sealed); err != nil {
+		return fmt.Errorf("seal: %w", err)
+	}
+	opened, err := aead.Open(nil, nonce, ciphertext, additionalData)
+	if err != nil {
+		return fmt.Errorf("open: %w", err)
+	}
+	return checkAnswer(opened, hex.EncodeToString(plaintext))
+}
+
+// selfTestECDSA verifies a known signature. ECDSA signatures are randomized,
+// so signing is checked by verifying a fresh signature.
+func selfTestECDSA() error {
+	key, err := parseSelfTestKey(ecdsaSelfTestKey)
+	if err != nil {
+		return err
+	}
+	ecdsaKey, ok := key.(*ecdsa.PrivateKey)
+	if !ok {
+		return fmt.Errorf("not an ECDSA key: %T", key)
+	}
+
+	digest := sha256.Sum256(selfTestMessage)
+	signature, _ := hex.DecodeString("3045022100be3ccd3e432722ca35d93413c45a57238111f92ebf1afd99b6cb07683fe2712d02203a5196701f04b86336d42f42dfb2fd04e5d807bd49929b842d180d36c42fa632")
+	if !ecdsa.VerifyASN1(&ecdsaKey.PublicKey, digest[:], signature) {
+		return errors.New("known signature doesn't verify")
+	}
+	signature, err = ecdsa.SignASN1(rand.Reader, ecdsaKey, digest[:])
+	if err != nil {
+		return fmt.Errorf("sign: %w", err)
+	}
+	if !ecdsa.VerifyASN1(&ecdsaKey.PublicKey, digest[:], signature) {
+		return errors.New("new signature doesn't verify")
+	}
+	return nil
+}
+
+// selfTestRSA checks the deterministic PKCS #1 v1.5 signature of the test
+// message and verifies it.
+func selfTestRSA() error {
+	key, err := parseSelfTestKey(rsaSelfTestKey)
+	if err != nil {
+		return err
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return fmt.Errorf("not an RSA key: %T", key)
+	}
+
+	digest := sha256.Sum256(selfTestMessage)
+	signature, err := rsa.SignPKCS1v15(nil, rsaKey, crypto.SHA256, digest[:])
+	if err != nil {
+		return fmt.Errorf("sign: %w", err)
+	}
+	if err := checkAnswer(signature, "7549caea19bb3e28e60e901738a86a62ad628dff646d9171ecd0d6fc228e5f0dd2ae3cb8c34e8cdcf7922c7be4db7673c5d7758ba83e258b0c7eec23ec5197178d6ada34246e9632aa8d76360884214282e41c08737b85ed13e0dbcc70e6a20258e742783f44e55f849300d8047f1b8ea3b6b949e8b6355d5070166c9a3cd83409c90378ab035146675264c28a3b3f0880a70975671dd3d8d408dfebc811e5c29ff35c3eb5fa854fd5c43ca50fe1051d9c596603cbd6488455bea629c48f206c9ea44e962d3233a443df684eaa5f28c1048d627602e288904b28ca3153d17f57b9f6ecea2271bbee7ab54872a3846cc28c166f3988d78292b9292982588ef95a"); err != nil {
+		return err
+	}
+	return rsa.VerifyPKCS1v15(&rsaKey.PublicKey, crypto.SHA256, digest[:], signature)
+}
+
+func parseSelfTestKey(encoded string) (any, error) {
+	der, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(encoded), ""))
+	if err != nil {
+		return nil, err
+	}
+	return x509.ParsePKCS8PrivateKey(der)
+}
@@ -92,7 +92,8 @@ exporters:
   otlphttp:
     endpoint: https://localhost:8443
     tls:
-      insecure_skip_verify: true
+      # the self-signed certificate is its own CA, FIPS collectors reject insecure_skip_verify
+      ca_file: /certs/server.crt
 
 service:
   pipelines:
@@ -126,6 +127,9 @@ start_collector() {
             for i in {1..15}; do
                 if docker logs "$CONTAINER_NAME" 2>&1 | grep -q "running in FIPS mode"; then
                     print_status "success" "BoringSSL FIPS module is enabled"
+                    if docker logs "$CONTAINER_NAME" 2>&1 | grep -q "FIPS self-tests passed"; then
+                        print_status "success" "Known-answer self-tests passed"
+                    fi
 
                     # Quick test to see what server negotiates by default
                     print_status "info" "Testing default server cipher negotiation..."
@@ -0,0 +1,32 @@
+// Copyright New Relic, Inc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build nrdot_pgo
+
+package main
+
+import (
+	"log"
+	"net/http"
+	_ "net/http/pprof"
+	"os"
+)
+
+func init() {
+	servePprof()
+}
+
+// servePprof exposes the runtime profiles of the collector so a CPU profile
+// can be collected, see scripts/build/collect-pgo-profile.sh.
+func servePprof() {
+	addr := os.Getenv("NRDOT_PPROF_ADDR")
+	if addr == "" {
+		return
+	}
+	go func() {
+		log.Printf("Serving pprof on %s", addr)
+		if err := http.ListenAndServe(addr, nil); err != nil {
+			log.Printf("ERROR: pprof server failed: %v", err)
+		}
+	}()
+}
@@ -4,14 +4,16 @@
 
 REPO_DIR="$(git rev-parse --show-toplevel)"
 BUILDER=''
+NRDOT_BUILDER=''
 
 # default values
 skipcompilation=true
 validate=true
 fips=false
+fips_module=boringcrypto
 cgo=0
 
-while getopts d:s:l:b:f: flag
+while getopts d:s:l:b:n:f:m: flag
 
 do
     case "${flag}" in
@@ -19,12 +21,15 @@ do
         s) skipcompilation=${OPTARG};;
         l) validate=${OPTARG};;
         b) BUILDER=${OPTARG};;
+        n) NRDOT_BUILDER=${OPTARG};;
         f) fips=${OPTARG};;
+        m) fips_module=${OPTARG};;
         *) exit 1;;
     esac
 done
 
 [[ -n "$BUILDER" ]] || BUILDER='ocb'
+[[ -n "$NRDOT_BUILDER" ]] || NRDOT_BUILDER='nrdot-collector-builder'
 
 if [[ -z $distributions ]]; then
     echo "List of distributions to build not provided. Use '-d' to specify the names of the distributions to build. Ex.:"
@@ -32,6 +37,22 @@ if [[ -z $distributions ]]; then
     exit 1
 fi
 
+# The FIPS module attested by the files copied into the FIPS sources: boringcrypto
+# needs cgo and its known-answer self-tests, Go's native FIPS 140-3 module is
+# enabled by GOFIPS140 at build time and runs its own.
+case "$fips_module" in
+    boringcrypto) fips_files="fips.go selftest.go";;
+    native) fips_files="fips_native.go";;
+    *)
+        echo "❌ ERROR: unknown FIPS module '${fips_module}', must be boringcrypto or native."
+        exit 1;;
+esac
+
+# The confmap converter of FIPS builds reports their FIPS status and rejects
+# TLS settings FIPS mode can't satisfy once the configuration is resolved. It is copied into the FIPS sources so that they
+# build on their own.
+fips_converter=github.com/newrelic/nrdot-collector-releases/fips/fipsconverter
+
 if [[ "$skipcompilation" = true ]]; then
     echo "Skipping the compilation, we'll only generate the sources."
 elif [[ "$fips" == true ]]; then
@@ -50,13 +71,22 @@ do
     build_folder="_build"
 
     if [[ "$fips" == true ]]; then
+      # Each FIPS module has a manifest and sources of its own, so that both
+      # variants can be built from one tree.
+      suffix="-fips"
+      if [[ "$fips_module" == native ]]; then
+        suffix="-fips-native"
+      fi
       yq eval '
-         .dist.name += "-fips" |
-         .dist.description += "-fips" |
-         .dist.output_path += "-fips"' manifest.yaml > manifest-fips.yaml
-      manifest_file="manifest-fips.yaml"
-      build_folder="_build-fips"
-      cgo=1
+         .dist.name += "'"${suffix}"'" |
+         .dist.description += "'"${suffix}"'" |
+         .dist.output_path += "'"${suffix}"'" |
+         .converters += [{"gomod": "'"${fips_converter}"' v0.0.0", "path": "../../fips/fipsconverter"}]' manifest.yaml > "manifest${suffix}.yaml"
+      manifest_file="manifest${suffix}.yaml"
+      build_folder="_build${suffix}"
+      if [[ "$fips_module" == boringcrypto ]]; then
+        cgo=1
+      fi
     fi
 
     mkdir -p $build_folder
@@ -65,11 +95,23 @@ do
     echo "Using Builder: $(command -v "$BUILDER")"
     echo "Using Go: $(command -v go)"
     echo "Using FIPS: ${fips}"
+    if [[ "$fips" == true ]]; then
+        echo "Using FIPS module: ${fips_module}"
+    fi
 
-    if CGO_ENABLED=${cgo} "$BUILDER" --skip-compilation="${skipcompilation}" --config ${manifest_file} > ${build_folder}/build.log 2>&1; then
+    if CGO_ENABLED=${cgo} "$BUILDER" --skip-compilation="${skipcompilation}" --config ${manifest_file} > ${build_folder}/build.log 2>&1 \
+        && "$NRDOT_BUILDER" manifest buildinfo --config ${manifest_file} --output ${build_folder}/buildinfo.go --fips="${fips}" >> ${build_folder}/build.log 2>&1; then
         if [[ "$fips" == true ]]; then
-            echo "Copying fips.go into _build-fips."
-            cp ../../fips/fips.go ./$build_folder
+            echo "Copying ${fips_files} and fipsconverter into ${build_folder}."
+            rm -f ./$build_folder/fips.go ./$build_folder/selftest.go ./$build_folder/fips_native.go ./$build_folder/tlsguard.go
+            for fips_file in ${fips_files}; do
+                cp "../../fips/${fips_file}" ./$build_folder
+            done
+            rm -rf ./$build_folder/fipsconverter
+            mkdir ./$build_folder/fipsconverter
+            find ../../fips/fipsconverter -maxdepth 1 \( -name '*.go' ! -name '*_test.go' -o -name 'go.mod' -o -name 'go.sum' \) \
+                -exec cp {} ./$build_folder/fipsconverter \;
+            (cd $build_folder && go mod edit -dropreplace="${fips_converter}@v0.0.0" -replace="${fips_converter}=./fipsconverter")
         fi
         echo "✅ SUCCESS: distribution '${distribution}' built."
     else
@@ -0,0 +1,112 @@
+#!/bin/bash
+# Copyright New Relic, Inc. All rights reserved.
+# SPDX-License-Identifier: Apache-2.0
+
+# Collects a CPU profile of a distribution's collector running its default
+# config under synthetic OTLP load, and writes it to the distribution's
+# default.pgo, which the goreleaser builds then optimize with. Distributions
+# without a default config.yaml are skipped.
+# Requires the sources generated by `make build`.
+set -e
+
+REPO_DIR="$(git rev-parse --show-toplevel)"
+
+# default values
+seconds=60
+workers=4
+pprof_addr="localhost:1777"
+# the default config receives the load through the OTLP gRPC receiver
+otlp_host=localhost
+otlp_port=4317
+
+while getopts d:t:w: flag
+do
+    case "${flag}" in
+        d) distributions=${OPTARG};;
+        t) seconds=${OPTARG};;
+        w) workers=${OPTARG};;
+        *) exit 1;;
+    esac
+done
+
+if [[ -z $distributions ]]; then
+    echo "List of distributions to profile not provided. Use '-d' to specify the names of the distributions to profile. Ex.:"
+    echo "$0 -d nrdot-collector"
+    exit 1
+fi
+
+tmp_dir=$(mktemp -d)
+pids=()
+cleanup() {
+    for pid in "${pids[@]}"; do
+        kill "$pid" 2> /dev/null || true
+    done
+    rm -rf "$tmp_dir"
+}
+trap cleanup EXIT
+
+for distribution in $(echo "$distributions" | tr "," "\n")
+do
+    dist_dir="${REPO_DIR}/distributions/${distribution}"
+    if [[ ! -f "${dist_dir}/config.yaml" ]]; then
+        echo "⚠️ Skipping '${distribution}', it has no default config.yaml to profile."
+        continue
+    fi
+    if [[ ! -d "${dist_dir}/_build" ]]; then
+        echo "❌ ERROR: sources of '${distribution}' not found, run 'make build' first."
+        exit 1
+    fi
+
+    echo "Building '${distribution}' with pprof"
+    cp "${REPO_DIR}/pgo/pprof.go" "${dist_dir}/_build/pprof.go"
+    (cd "${dist_dir}/_build" && CGO_ENABLED=0 go build -tags nrdot_pgo -trimpath -o "${tmp_dir}/collector" .)
+    rm -f "${dist_dir}/_build/pprof.go"
+
+    contrib_version=$(grep -m1 -oE 'github.com/open-telemetry/opentelemetry-collector-contrib/[^ ]+ v[0-9.]+' "${dist_dir}/manifest.yaml" | cut -d' ' -f2)
+    echo "Installing telemetrygen ${contrib_version}"
+    GOBIN="$tmp_dir" go install "github.com/open-telemetry/opentelemetry-collector-contrib/cmd/telemetrygen@${contrib_version}"
+
+    "${tmp_dir}/collector" --config "${REPO_DIR}/pgo/sink.yaml" > "${tmp_dir}/sink.log" 2>&1 &
+    pids+=($!)
+
+    OTEL_EXPORTER_OTLP_ENDPOINT="http://localhost:14318" NEW_RELIC_LICENSE_KEY="pgo" NRDOT_PPROF_ADDR="$pprof_addr" \
+        "${tmp_dir}/collector" --config "${dist_dir}/config.yaml" > "${tmp_dir}/collector.log" 2>&1 &
+    pids+=($!)
+
+    # The OTLP receiver only listens once the pipelines have started, which
+    # doesn't depend on the config having a health_check extension.
+    echo "Waiting for the collector to receive OTLP"
+    ready=false
+    for _ in $(seq 30); do
+        if (exec 3<> "/dev/tcp/${otlp_host}/${otlp_port}") 2> /dev/null; then
+            ready=true
+            break
+        fi
+        sleep 1
+    done
+    if [[ "$ready" != true ]]; then
+        echo "❌ ERROR: collector of '${distribution}' doesn't receive OTLP on ${otlp_host}:${otlp_port}."
+        cat "${tmp_dir}/collector.log"
+        exit 1
+    fi
+
+    # The load outlasts the profile so that it covers a steady state.
+    load_duration="$((seconds + 15))s"
+    for signal in traces metrics logs; do
+        "${tmp_dir}/telemetrygen" "$signal" --otlp-insecure --otlp-endpoint "${otlp_host}:${otlp_port}" \
+            --duration "$load_duration" --rate 0 --workers "$workers" > "${tmp_dir}/telemetrygen-${signal}.log" 2>&1 &
+        pids+=($!)
+    done
+    sleep 5
+
+    echo "Profiling '${distribution}' for ${seconds}s"
+    curl -sf "http://${pprof_addr}/debug/pprof/profile?seconds=${seconds}" -o "${dist_dir}/default.pgo"
+
+    for pid in "${pids[@]}"; do
+        kill "$pid" 2> /dev/null || true
+    done
+    wait 2> /dev/null || true
+    pids=()
+
+    echo "✅ SUCCESS: wrote ${dist_dir}/default.pgo, run 'make generate-goreleaser' if it is new."
+done
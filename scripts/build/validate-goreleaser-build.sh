@@ -98,3 +98,51 @@ else
     done
     echo "✅ Archives and Packages validated!"
 fi
+
+echo "📋 Verifying SBOMs exist..."
+sboms=$( jq -r '.[] | select(.type == "SBOM") | .path' dist/artifacts.json )
+if [ -z "${sboms}" ]; then
+    echo "❌ No SBOMs found in artifacts.json"
+    exit 1
+fi
+for sbom in $sboms; do
+    if [ ! -s "${sbom}" ]; then
+        echo "❌ ${sbom} not found or empty!"
+        exit 1
+    else
+        echo "Found: ${sbom}"
+    fi
+done
+echo "✅ All SBOMs found!"
+
+echo "📋 Verifying build provenance..."
+for provenance in $( jq -r '.[] | select(.type == "SBOM") | .path | select(endswith(".provenance.json"))' dist/artifacts.json ); do
+    if ! jq -e '.predicateType == "https://slsa.dev/provenance/v1" and (.subject | length > 0)' "${provenance}" > /dev/null; then
+        echo "❌ ${provenance} is not a valid SLSA provenance!"
+        exit 1
+    fi
+    echo "Found: ${provenance}"
+done
+echo "✅ Build provenance valid!"
+
+echo "📋 Verifying build info..."
+commit=$( jq -r '.commit' dist/metadata.json )
+for binary in $( jq -r '.[] | select(.type == "Binary" and .goos == "linux" and .goarch == "amd64") | .path' dist/artifacts.json ); do
+    if ! "${binary}" build-info | jq -e --arg commit "${commit}" '.commit == $commit and .versions.betaCoreVersion != ""' > /dev/null; then
+        echo "❌ ${binary} does not report its build info!"
+        exit 1
+    fi
+    echo "Found: ${binary}"
+done
+echo "✅ Build info reported!"
+
+echo "📋 Verifying binary inspection reports..."
+for binary in $binaries; do
+    report="$(dirname "${binary}")/inspect.json"
+    if ! jq -e '.passed' "${report}" > /dev/null 2>&1; then
+        echo "❌ ${report} not found or ${binary} failed its inspection!"
+        exit 1
+    fi
+    echo "Found: ${report}"
+done
+echo "✅ All binaries match their build settings!"
@@ -7,13 +7,15 @@ set -e
 
 # default values
 fips=false
+fips_module=boringcrypto
 distributions=""
 
-while getopts d:f: flag
+while getopts d:f:m: flag
 do
     case "${flag}" in
         d) distributions=${OPTARG};;
         f) fips=${OPTARG};;
+        m) fips_module=${OPTARG};;
         *) exit 1;;
     esac
 done
@@ -25,17 +27,24 @@ fi
 
 files=(
     "components.go" "go.mod" "go.sum" "main_others.go"
-    "main_windows.go" "main.go" # build.log excluded as it is not cached
+    "main_windows.go" "main.go" "buildinfo.go" # build.log excluded as it is not cached
 )
+if [ ${fips} = true ] && [ ${fips_module} = native ]; then
+    files+=("fips_native.go")
+elif [ ${fips} = true ]; then
+    files+=("fips.go" "selftest.go")
+fi
 if [ ${fips} = true ]; then
-    files+=("fips.go")
+    files+=("fipsconverter/go.mod")
 fi
 
 overall_exit=0
 
 for distribution in $(echo "$distributions" | tr "," "\n"); do
     path="distributions/${distribution}/_build"
-    if [ ${fips} = true ]; then
+    if [ ${fips} = true ] && [ ${fips_module} = native ]; then
+        path="${path}-fips-native"
+    elif [ ${fips} = true ]; then
         path="${path}-fips"
     fi
     if [ ! -d "$path" ]; then
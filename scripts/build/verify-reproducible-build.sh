@@ -0,0 +1,77 @@
+#!/bin/bash
+# Copyright New Relic, Inc. All rights reserved.
+# SPDX-License-Identifier: Apache-2.0
+
+# Builds a snapshot of a distribution twice with goreleaser and compares the
+# checksums of the binaries, archives and packages, which must be identical.
+# MSIs, images and SBOMs aren't covered. Requires the sources generated by
+# `make build`.
+set -e
+
+REPO_DIR="$(git rev-parse --show-toplevel)"
+GORELEASER=''
+
+# default values
+fips=false
+fips_module=boringcrypto
+
+while getopts d:f:m:g: flag
+do
+    case "${flag}" in
+        d) distributions=${OPTARG};;
+        f) fips=${OPTARG};;
+        m) fips_module=${OPTARG};;
+        g) GORELEASER=${OPTARG};;
+        *) exit 1;;
+    esac
+done
+
+[[ -n "$GORELEASER" ]] || GORELEASER='goreleaser'
+
+if [[ -z $distributions ]]; then
+    echo "List of distributions to verify not provided. Use '-d' to specify the names of the distributions to verify. Ex.:"
+    echo "$0 -d nrdot-collector"
+    exit 1
+fi
+
+goreleaser_file=".goreleaser.yaml"
+if [[ "$fips" == true && "$fips_module" == native ]]; then
+    goreleaser_file=".goreleaser-fips-native.yaml"
+elif [[ "$fips" == true ]]; then
+    goreleaser_file=".goreleaser-fips.yaml"
+fi
+
+tmp_dir=$(mktemp -d)
+trap 'rm -rf "$tmp_dir"' EXIT
+
+# Prints the checksum of every binary, archive and package of a dist folder.
+checksums() {
+    jq -r '.[] | select(.type == "Binary" or .type == "Archive" or .type == "Linux Package") | .path' "$1/artifacts.json" \
+        | sed "s|^dist/||" | sort | (cd "$1" && xargs sha256sum)
+}
+
+overall_exit=0
+
+for distribution in $(echo "$distributions" | tr "," "\n")
+do
+    pushd "${REPO_DIR}/distributions/${distribution}" > /dev/null || exit
+
+    for run in 1 2; do
+        echo "Building snapshot ${run} of '${distribution}' with ${goreleaser_file}"
+        GPG_KEY_PATH='' APK_KEY_PATH='' GPG_FINGERPRINT='' REGISTRY='localhost' "$GORELEASER" release --snapshot --clean \
+            --skip=publish,sign,sbom,docker,validate --config "$goreleaser_file" > "${tmp_dir}/goreleaser-${run}.log" 2>&1 \
+            || { cat "${tmp_dir}/goreleaser-${run}.log"; exit 1; }
+        checksums dist > "${tmp_dir}/checksums-${run}.txt"
+    done
+
+    if diff "${tmp_dir}/checksums-1.txt" "${tmp_dir}/checksums-2.txt"; then
+        echo "✅ SUCCESS: $(wc -l < "${tmp_dir}/checksums-1.txt") artifacts of '${distribution}' are reproducible."
+    else
+        echo "❌ ERROR: artifacts of '${distribution}' differ between builds."
+        overall_exit=1
+    fi
+
+    popd > /dev/null || exit
+done
+
+exit ${overall_exit}
@@ -3,12 +3,14 @@
 # SPDX-License-Identifier: Apache-2.0
 
 GO=''
+check=false
 
-while getopts d:g: flag
+while getopts d:g:c flag
 do
     case "${flag}" in
         d) distributions=${OPTARG};;
         g) GO=${OPTARG};;
+        c) check=true;;
         *) exit 1;;
     esac
 done
@@ -21,10 +23,51 @@ if [[ -z $distributions ]]; then
     exit 1
 fi
 
-echo "Generating goreleaser files for distributions: $distributions";
+failed=false
+
+# generate writes the goreleaser file for the given generator arguments, or
+# with -c compares it with the committed file.
+generate() {
+    local file=$1
+    shift
+    if [[ "$check" == true ]]; then
+        ${GO} run cmd/goreleaser/main.go "$@" -check "${file}" || failed=true
+    else
+        ${GO} run cmd/goreleaser/main.go "$@" > "${file}"
+    fi
+}
+
+if [[ "$check" == true ]]; then
+    echo "Checking goreleaser files for distributions: $distributions";
+else
+    echo "Generating goreleaser files for distributions: $distributions";
+fi
 
 for distribution in $(echo "$distributions" | tr "," "\n")
 do
-    ${GO} run cmd/goreleaser/main.go -d "${distribution}" > "./distributions/${distribution}/.goreleaser.yaml"
-    ${GO} run cmd/goreleaser/main.go -d "${distribution}" -f > "./distributions/${distribution}/.goreleaser-fips.yaml"
+    generate "./distributions/${distribution}/.goreleaser.yaml" -d "${distribution}"
+    generate "./distributions/${distribution}/.goreleaser-fips.yaml" -d "${distribution}" -f
+    # FIPS variant built with Go's native FIPS 140-3 module instead of boringcrypto
+    generate "./distributions/${distribution}/.goreleaser-fips-native.yaml" -d "${distribution}" -f -fips-module native
 done
+
+# Combined project releasing all distributions and their FIPS variants in one goreleaser run from ./distributions.
+# It covers every distribution with a release profile whichever are given, so that CI checks it from any of them.
+all_distributions=$(cd ./distributions && for distribution in $(ls); do
+    [[ -f "${distribution}/release.yaml" ]] && echo "${distribution}"
+done | paste -sd, -)
+generate "./distributions/.goreleaser.yaml" -d "${all_distributions}" -fips both
+
+# The systemd drop-ins packages install for the NRDOT_MODE service modes and
+# the package scripts, units and environment files of every variant, rendered
+# from the *.tmpl templates. The generator tests check that the committed ones
+# are up to date.
+if [[ "$check" != true ]]; then
+    ${GO} run cmd/goreleaser/main.go -d "${distributions}" -fips both -package-files
+    ${GO} run cmd/goreleaser/main.go -d "${distributions}" -f -fips-module native -package-files
+fi
+
+if [[ "$failed" == true ]]; then
+    echo "Check failed: The goreleaser templates have changed but the .goreleaser.yamls haven't. Run 'make generate-goreleaser' and update your PR."
+    exit 1
+fi
@@ -38,6 +38,10 @@ do
     -rules "${REPO_DIR}/internal/assets/license/rules.json" \
     -noticeTemplate "${REPO_DIR}/internal/assets/license/THIRD_PARTY_NOTICES.md.tmpl" \
     -noticeOut "${REPO_DIR}/distributions/${distribution}/${NOTICE_FILE}"
+  # UBI images ship the license next to the notices, other distributions don't need a copy
+  if [[ -f "${REPO_DIR}/distributions/${distribution}/Dockerfile.ubi" ]]; then
+    cp "${REPO_DIR}/LICENSE" "${REPO_DIR}/distributions/${distribution}/LICENSE"
+  fi
 
   popd > /dev/null || exit
 done